@@ -5,6 +5,19 @@ type Drop interface {
 	ToLiquid() interface{}
 }
 
+// PropertyDrop is a Drop that resolves its own properties.
+//
+// A property access such as {{ product.price }} or {{ product["price"] }} calls LiquidProperty, instead of
+// looking up the property on the ToLiquid value. If the second return value is false, the property is
+// looked up on the ToLiquid value. Other operations, such as output, comparison, and iteration,
+// use the ToLiquid value.
+//
+// This can be used to implement lazy or computed properties.
+type PropertyDrop interface {
+	Drop
+	LiquidProperty(name string) (interface{}, bool)
+}
+
 // FromDrop returns returns object.ToLiquid() if object's type implement this function;
 // else the object itself.
 func FromDrop(object interface{}) interface{} {
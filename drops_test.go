@@ -90,3 +90,37 @@ func ExampleDrop_struct() {
 	fmt.Println(out)
 	// Output: blue AWD Model S85
 }
+
+type productDrop struct{ basePrice, quantity int }
+
+func (p productDrop) ToLiquid() interface{} { return "product" }
+
+func (p productDrop) LiquidProperty(name string) (interface{}, bool) {
+	if name == "total" {
+		return p.basePrice * p.quantity, true
+	}
+	return nil, false
+}
+
+func TestPropertyDrop(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]interface{}{
+		"product":  productDrop{5, 3},
+		"products": []interface{}{productDrop{1, 2}, productDrop{2, 3}},
+	}
+	tests := []struct{ in, expected string }{
+		{`{{ product.total }}`, "15"},
+		{`{{ product["total"] }}`, "15"},
+		{`{{ product }}`, "product"},
+		{`{{ product.size }}`, "7"},
+		{`{% if product.total > 10 %}big{% endif %}`, "big"},
+		{`{% for p in products %}{{ p.total }} {% endfor %}`, "2 6 "},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			out, err := engine.ParseAndRenderString(test.in, bindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, out, test.in)
+		})
+	}
+}
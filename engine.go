@@ -2,6 +2,7 @@ package liquid
 
 import (
 	"io"
+	"reflect"
 
 	"github.com/osteele/liquid/filters"
 	"github.com/osteele/liquid/render"
@@ -11,17 +12,38 @@ import (
 // An Engine parses template source into renderable text.
 //
 // An engine can be configured with additional filters and tags.
-type Engine struct{ cfg render.Config }
+type Engine struct {
+	cfg render.Config
+	// templates caches the templates that RenderTemplateString compiles
+	templates templateCache
+}
 
 // NewEngine returns a new Engine.
 func NewEngine() *Engine {
-	e := Engine{render.NewConfig()}
+	e := Engine{cfg: render.NewConfig()}
 	filters.AddStandardFilters(&e.cfg)
 	tags.AddStandardTags(e.cfg)
 	return &e
 }
 
+// Clone returns a new Engine with a copy of e's configuration. Filters, tags, blocks,
+// globals, settings, and translations that are registered on either engine don't affect
+// the other, so a clone can be customized for a single request without changing a
+// shared base engine.
+//
+// The clone starts with empty template caches.
+func (e *Engine) Clone() *Engine {
+	c := Engine{cfg: e.cfg.Clone()}
+	e.templates.mu.Lock()
+	c.templates.size, c.templates.set = e.templates.size, e.templates.set
+	e.templates.mu.Unlock()
+	return &c
+}
+
 // RegisterBlock defines a block e.g. {% tag %}…{% endtag %}.
+//
+// The renderer can call ctx.InnerString to render the block's body, or ctx.InnerSource
+// to retrieve its unparsed source. ctx.TagArgs returns the arguments of the start tag.
 func (e *Engine) RegisterBlock(name string, td Renderer) {
 	e.cfg.AddBlock(name).Renderer(func(w io.Writer, ctx render.Context) error {
 		s, err := td(ctx)
@@ -31,6 +53,7 @@ func (e *Engine) RegisterBlock(name string, td Renderer) {
 		_, err = io.WriteString(w, s)
 		return err
 	})
+	e.clearCompiledTemplates()
 }
 
 // RegisterFilter defines a Liquid filter, for use as `{{ value | my_filter }}` or `{{ value | my_filter: arg }}`.
@@ -38,17 +61,87 @@ func (e *Engine) RegisterBlock(name string, td Renderer) {
 // A filter is a function that takes at least one input, and returns one or two outputs.
 // If it returns two outputs, the second must have type error.
 //
+// A filter whose first parameter has type FilterContext is passed the context in which it is
+// applied, followed by its input and arguments. It can use this to read the values that
+// SetSetting defines, and the template variables.
+//
+// A filter whose last parameter has type expressions.KeywordArgs receives the keyword
+// arguments, such as scale: 2 in {{ image | img_url: '580x', scale: 2 }}, in that parameter.
+//
 // Examples:
 //
 // * https://github.com/osteele/liquid/blob/main/filters/standard_filters.go
 //
 // * https://github.com/osteele/gojekyll/blob/master/filters/filters.go
+//
+// Filters are resolved when a template is rendered, not when it is parsed. A filter that
+// is registered after a template is parsed is available when the template is subsequently rendered.
 func (e *Engine) RegisterFilter(name string, fn interface{}) {
 	e.cfg.AddFilter(name, fn)
 }
 
+// RegisterFilterPipeline defines a filter that applies a sequence of filters. For example,
+// after RegisterFilterPipeline("clean", "strip_html | strip | downcase"), {{ x | clean }} is
+// equivalent to {{ x | strip_html | strip | downcase }}.
+//
+// The filters in the pipeline must already be registered.
+func (e *Engine) RegisterFilterPipeline(name, pipeline string) error {
+	return e.cfg.AddFilterPipeline(name, pipeline)
+}
+
+// AddJekyllFilters defines the filters that Jekyll adds to the standard Liquid filters,
+// such as slugify, jsonify, and where_exp.
+//
+// These don't include markdownify, since this requires a Markdown processor.
+// Use RegisterFilter to define it.
+func (e *Engine) AddJekyllFilters() {
+	filters.AddJekyllFilters(&e.cfg)
+}
+
+// AddShopifyFilters defines the filters that Shopify adds to the standard Liquid filters,
+// for use in storefront themes.
+//
+// money and money_with_currency use the "money_format" and "currency" settings, which
+// default to "${{amount}}" and "USD". Use SetSetting to change them.
+func (e *Engine) AddShopifyFilters() {
+	filters.AddShopifyFilters(&e.cfg)
+}
+
+// RegisterStringer defines how {{ value }} renders a value of type t: as the string that fn returns.
+// Use this for types that can't be given a String or ToLiquid method, such as those from other packages.
+func (e *Engine) RegisterStringer(t reflect.Type, fn func(interface{}) string) {
+	e.cfg.RegisterStringer(t, fn)
+}
+
+// SetGlobal defines a variable that every template rendered by the engine can refer to,
+// such as a site name. A binding with the same name, or a variable that the template
+// assigns, takes precedence over it within that rendering.
+func (e *Engine) SetGlobal(name string, value interface{}) {
+	e.cfg.Globals[name] = value
+}
+
+// AddTranslations adds translations, by key, for the locale, for use by the t filter:
+// {{ "cart.count" | t: count: cart.size }}. A translation can contain placeholders such as
+// %{count}, which are replaced by the filter's keyword arguments.
+//
+// The filter uses the locale that the "locale" setting names, and then the "default_locale"
+// setting, which defaults to "en". Use SetSetting to set these.
+func (e *Engine) AddTranslations(locale string, translations map[string]string) {
+	e.cfg.AddTranslations(locale, translations)
+}
+
+// SetSetting defines a value, such as a locale, that filters can read from FilterContext.Config().Settings.
+func (e *Engine) SetSetting(name string, value interface{}) {
+	e.cfg.Settings[name] = value
+}
+
 // RegisterTag defines a tag e.g. {% tag %}.
 //
+// The renderer is called each time the tag is rendered. ctx.TagArgs returns the text
+// of the tag's arguments, which ctx.EvaluateString can evaluate as an expression;
+// ctx.Get looks up a variable. An error returned by the renderer is reported with the
+// tag's source location.
+//
 // Further examples are in https://github.com/osteele/gojekyll/blob/master/tags/tags.go
 func (e *Engine) RegisterTag(name string, td Renderer) {
 	// For simplicity, don't expose the two stage parsing/rendering process to clients.
@@ -63,13 +156,31 @@ func (e *Engine) RegisterTag(name string, td Renderer) {
 			return err
 		}, nil
 	})
+	e.clearCompiledTemplates()
 }
 
-// StrictVariables causes the renderer to error when the template contains an undefined variable.
+// StrictVariables causes the renderer to error when the template refers to an undefined variable,
+// for example in {{ missing }}, {% if missing %}, or {% assign x = missing %}.
+//
+// By default, an undefined variable evaluates to nil.
 func (e *Engine) StrictVariables() {
 	e.cfg.StrictVariables = true
 }
 
+// DottedKeyFallback causes a property chain such as {{ a.b }}, if it evaluates to nil, to
+// evaluate to the variable whose name is the literal "a.b". Use this for bindings whose keys contain dots.
+//
+// By default, a.b always refers to the b property of a.
+func (e *Engine) DottedKeyFallback() {
+	e.cfg.DottedKeyFallback = true
+}
+
+// LaxFilters causes the renderer to pass the input of an undefined filter through unchanged,
+// as Shopify Liquid does. By default, an undefined filter is an error.
+func (e *Engine) LaxFilters() {
+	e.cfg.StrictFilters = false
+}
+
 // ParseTemplate creates a new Template using the engine configuration.
 func (e *Engine) ParseTemplate(source []byte) (*Template, SourceError) {
 	return newTemplate(&e.cfg, source, "", 0)
@@ -116,11 +227,95 @@ func (e *Engine) ParseAndRenderString(source string, b Bindings) (string, Source
 	return string(bs), nil
 }
 
+// RenderTemplateString renders source with the bindings. Unlike ParseAndRenderString, it
+// caches the compiled template, so that rendering the same source again, with the same
+// or different bindings, doesn't parse it again.
+//
+// The cache holds the DefaultTemplateCacheSize most recently used templates, or the number
+// set by SetTemplateCacheSize. A source that doesn't parse isn't cached.
+func (e *Engine) RenderTemplateString(source string, b Bindings) (string, SourceError) {
+	tpl, ok := e.templates.get(source)
+	if !ok {
+		var err SourceError
+		tpl, err = e.ParseString(source)
+		if err != nil {
+			return "", err
+		}
+		e.templates.put(source, tpl)
+	}
+	return tpl.RenderString(b)
+}
+
+// SetTemplateCacheSize sets the number of compiled templates that RenderTemplateString caches,
+// and removes the templates that it has already cached. A value of zero or less disables the cache.
+func (e *Engine) SetTemplateCacheSize(n int) *Engine {
+	e.templates.setSize(n)
+	return e
+}
+
+// ClearTemplateCache removes the compiled templates that RenderTemplateString has cached.
+func (e *Engine) ClearTemplateCache() {
+	e.templates.clear()
+}
+
 // Delims sets the action delimiters to the specified strings, to be used in subsequent calls to
 // ParseTemplate, ParseTemplateLocation, ParseAndRender, or ParseAndRenderString. An empty delimiter
 // stands for the corresponding default: objectLeft = {{, objectRight = }}, tagLeft = {% , tagRight = %}
 func (e *Engine) Delims(objectLeft, objectRight, tagLeft, tagRight string) *Engine {
 	e.cfg.Delims = []string{objectLeft, objectRight, tagLeft, tagRight}
+	e.clearCompiledTemplates()
+	return e
+}
+
+// clearCompiledTemplates removes the templates that RenderTemplateString and {% include %}
+// have cached. A method that changes how templates are parsed calls this, so that a
+// template that was compiled with the previous configuration isn't reused.
+func (e *Engine) clearCompiledTemplates() {
+	e.templates.clear()
+	e.ClearIncludeCache()
+}
+
+// SetFileSystem sets the file system that {% include %} reads templates from, for example
+// an fs.FS wrapper or a database. By default, templates are read from the local file system.
+func (e *Engine) SetFileSystem(fs render.FileSystem) {
+	e.cfg.SetFileSystem(fs)
+}
+
+// ClearIncludeCache removes the compiled templates that {% include %} has cached.
+func (e *Engine) ClearIncludeCache() {
+	if e.cfg.IncludeCache != nil {
+		e.cfg.IncludeCache.Clear()
+	}
+}
+
+// DisableIncludeCache causes {% include %} to compile the included template each time it is rendered,
+// instead of reusing the compilation from a previous render of the same source.
+func (e *Engine) DisableIncludeCache() {
+	e.cfg.IncludeCache = nil
+}
+
+// MaxTemplateSize limits the length in bytes of a template source, to be used in subsequent calls to
+// ParseTemplate, ParseTemplateLocation, ParseAndRender, or ParseAndRenderString. A template that
+// is longer than this is rejected before it is parsed. This also applies to {% include %}d files.
+// A value of zero or less removes the limit.
+func (e *Engine) MaxTemplateSize(n int) *Engine {
+	e.cfg.MaxTemplateSize = n
+	e.clearCompiledTemplates()
+	return e
+}
+
+// MaxIterations limits the number of loop iterations in each subsequent rendering, counted across
+// all its {% for %} and {% tablerow %} loops, including those in {% include %}d files. A rendering
+// that exceeds this returns an error. A value of zero or less removes the limit.
+func (e *Engine) MaxIterations(n int) *Engine {
+	e.cfg.MaxIterations = n
+	return e
+}
+
+// MaxOutputSize limits the number of bytes that each subsequent rendering can write. A rendering
+// that exceeds this returns an error. A value of zero or less removes the limit.
+func (e *Engine) MaxOutputSize(n int) *Engine {
+	e.cfg.MaxOutputSize = n
 	return e
 }
 
@@ -3,11 +3,14 @@ package liquid
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/osteele/liquid/render"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,6 +22,8 @@ var liquidTests = []struct{ in, expected string }{
 	{`{{ page.title }}`, "Introduction"},
 	{`{% if x %}true{% endif %}`, "true"},
 	{`{{ "upper" | upcase }}`, "UPPER"},
+	{`{{ 3 | plus: 1 }} {{ 3.0 | plus: 1 }} {{ 1.5 | times: 2 }}`, "4 4.0 3.0"},
+	{`{{ 5 | divided_by: 2 }} {{ 5.0 | divided_by: 2 }} {{ 6 | divided_by: 2.0 }}`, "2 2.5 3.0"},
 }
 
 var testBindings = map[string]interface{}{
@@ -89,6 +94,65 @@ func TestEngine_ParseAndRenderString_struct(t *testing.T) {
 	require.Equal(t, "hello", str)
 }
 
+type testUser struct{ name string }
+
+func (u testUser) DisplayName() string { return strings.ToUpper(u.name) }
+
+func (u testUser) Balance() (int, error) { return 0, fmt.Errorf("account locked") }
+
+func TestEngine_ParseAndRenderString_methods(t *testing.T) {
+	params := map[string]interface{}{"user": testUser{"ada"}}
+	engine := NewEngine()
+	str, err := engine.ParseAndRenderString("{{ user.displayName }}", params)
+	require.NoError(t, err)
+	require.Equal(t, "ADA", str)
+
+	_, err = engine.ParseAndRenderString("{{ user.balance }}", params)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "account locked")
+}
+
+func TestEngine_ParseAndRenderString_assignCondition(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]interface{}{"list": []string{"a", "b"}, "target": "b", "a": 2, "b": 1}
+	tests := []struct{ in, expected string }{
+		{`{% assign found = list contains target %}{% if found %}found{% endif %}`, "found"},
+		{`{% assign found = (list contains "c") | default: "none" %}{{ found }}`, "none"},
+		{`{% assign n = (a > b) | append: "!" %}{{ n }}`, "true!"},
+		{`{% assign n = (a < b or a == 2) | upcase %}{{ n }}`, "TRUE"},
+		{`{% assign x = 'a,b,c' | split: ',' | first | upcase %}{{ x }}`, "A"},
+		{`{% assign x = 'c,a,b' | split: ',' | sort | join: '' | size | plus: 1 %}{{ x }}`, "4"},
+		{`{% assign x = list | reverse | join: '-' | upcase | append: '!' %}{{ x }}`, "B-A!"},
+		{`{{ 'a,b,c' | split: ',' | reverse | join: '-' | upcase | append: '!' }}`, "C-B-A!"},
+		{`{{ 'a b' | split: ' ' | map: 'size' | join: '+' | prepend: '=' }}`, "=1+1"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			out, err := engine.ParseAndRenderString(test.in, bindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, out, test.in)
+		})
+	}
+}
+
+func TestEngine_ParseAndRenderString_reassign(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]interface{}{"items": []string{"b", "c"}, "user": map[string]interface{}{"name": "ann"}}
+	tests := []struct{ in, expected string }{
+		{`{% assign s = "a" %}{% assign s = s | append: "b" %}{% assign s = s | upcase %}{{ s }}`, "AB"},
+		{`{% assign list = "a" | split: "," %}{% for item in items %}{% assign list = list | concat: items | uniq %}{% endfor %}{{ list | join: "," }}`, "a,b,c"},
+		{`{% assign n = 0 %}{% for item in items %}{% assign n = n | plus: 1 %}{% endfor %}{% if n == 2 %}{{ n }}{% endif %}`, "2"},
+		{`{% assign user.name = user.name | capitalize %}{% assign user.email = user.name | append: "@example.com" %}{{ user.email }}`, "Ann@example.com"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			out, err := engine.ParseAndRenderString(test.in, bindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, out, test.in)
+		})
+	}
+}
+
 func TestEngine_ParseAndRender_errors(t *testing.T) {
 	_, err := NewEngine().ParseAndRenderString("{{ syntax error }}", emptyBindings)
 	require.Error(t, err)
@@ -100,6 +164,441 @@ func TestEngine_ParseAndRender_errors(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestEngine_ParseAndRender_errorTypes(t *testing.T) {
+	engine := NewEngine()
+	_, err := engine.ParseString("{% undefined_tag %}")
+	require.Error(t, err)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, "undefined_tag", pe.Tag)
+	var re *RenderError
+	require.False(t, errors.As(err, &re))
+
+	engine.RegisterFilter("fail", func(interface{}) (interface{}, error) { return nil, errors.New("failed") })
+	tpl, err := engine.ParseTemplateLocation([]byte("line 1\n  {{ x | fail }}"), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.RenderString(emptyBindings)
+	require.Error(t, err)
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, "fail", re.Filter)
+	require.Equal(t, "page.html", re.Path())
+	require.Equal(t, 2, re.LineNo)
+	require.Equal(t, 3, re.ColNo)
+	require.False(t, errors.As(err, &pe))
+}
+
+func TestEngine_RegisterStringer(t *testing.T) {
+	type celsius float64
+	engine := NewEngine()
+	engine.RegisterStringer(reflect.TypeOf(celsius(0)), func(v interface{}) string {
+		return fmt.Sprintf("%.1f°C", v)
+	})
+	out, err := engine.ParseAndRenderString(`{{ t }}`, map[string]interface{}{"t": celsius(21.5)})
+	require.NoError(t, err)
+	require.Equal(t, "21.5°C", out)
+}
+
+func TestEngine_RegisterFilter_filterContext(t *testing.T) {
+	engine := NewEngine()
+	engine.SetSetting("locale", "fr")
+	engine.RegisterFilter("t", func(fc FilterContext, key string) string {
+		return fmt.Sprintf("%s[%v] for %v", key, fc.Config().Settings["locale"], fc.Get("name"))
+	})
+	out, err := engine.ParseAndRenderString(`{{ "greeting" | t | upcase }}`, map[string]interface{}{"name": "Ana"})
+	require.NoError(t, err)
+	require.Equal(t, "GREETING[FR] FOR ANA", out)
+}
+
+func TestEngine_RegisterFilter_predicateScope(t *testing.T) {
+	engine := NewEngine()
+	engine.AddJekyllFilters()
+	engine.SetGlobal("limit", 2)
+	engine.RegisterFilter("double", func(n int) int { return 2 * n })
+	bindings := map[string]interface{}{"nums": []int{1, 2, 3, 4}, "min": 1}
+
+	out, err := engine.ParseAndRenderString(`{{ nums | where_exp: "n", "(n | double) > limit" | join }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "2 3 4", out)
+	out, err = engine.ParseAndRenderString(`{{ nums | take_while: "n", "n <= limit" | join }}|{{ nums | drop_while: "n", "n == min" | join }}|{{ n }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "1 2|2 3 4|", out)
+}
+
+func TestEngine_RegisterFilterPipeline(t *testing.T) {
+	engine := NewEngine()
+	require.NoError(t, engine.RegisterFilterPipeline("clean", "strip_html | strip | downcase"))
+	bindings := map[string]interface{}{"x": "  <p>Hello <b>World</b></p> "}
+	out, err := engine.ParseAndRenderString(`{{ x | clean }}`, bindings)
+	require.NoError(t, err)
+	expected, err := engine.ParseAndRenderString(`{{ x | strip_html | strip | downcase }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, expected, out)
+	require.Equal(t, "hello world", out)
+
+	require.Error(t, engine.RegisterFilterPipeline("bad", "strip | undefined_filter"))
+}
+
+func TestEngine_ParseAndRenderString_capture(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]interface{}{"items": []string{"a", "b", "c"}}
+	tests := []struct{ in, expected string }{
+		{`{% capture csv %}a,b,c{% endcapture %}{% assign arr = csv | split: ',' %}{% for x in arr %}[{{ x }}]{% endfor %}{{ arr | size }}`, "[a][b][c]3"},
+		{`{% capture csv %}{% for x in items %}{{ x | upcase }}{% unless forloop.last %},{% endunless %}{% endfor %}{% endcapture %}{% assign arr = csv | split: ',' | reverse %}{{ arr | join: '-' }}`, "C-B-A"},
+		{`{% capture n %}{{ 3 }}{% endcapture %}{{ n | plus: 1 }}`, "4"},
+		{`{% capture s %}abc{% endcapture %}{{ s.size }} {{ s | size }}{% if s == "abc" %} equal{% endif %}`, "3 3 equal"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			out, err := engine.ParseAndRenderString(test.in, bindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, out, test.in)
+		})
+	}
+}
+
+func TestEngine_ParseAndRenderString_filterResultProperty(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]interface{}{"items": []map[string]interface{}{
+		{"name": "a", "tags": []string{"x", "y"}},
+		{"name": "b"},
+	}}
+	tests := []struct{ in, expected string }{
+		{`{{ (items | first).name }}`, "a"},
+		{`{{ items.first.name }}`, "a"},
+		{`{{ (items | last)["name"] }}`, "b"},
+		{`{{ (items | first).tags[1] }}`, "y"},
+		{`{{ (items | map: "name").last }}`, "b"},
+		{`{{ (items | first).name | upcase }}`, "A"},
+		{`{% assign name = (items | first).name %}{{ name }}`, "a"},
+		{`{% if (items | first).name == "a" %}yes{% endif %}`, "yes"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			out, err := engine.ParseAndRenderString(test.in, bindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, out, test.in)
+		})
+	}
+
+	_, err := engine.ParseAndRenderString(`{{ items | first.name }}`, bindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "syntax error")
+}
+
+func TestEngine_ParseAndRenderString_integerRounding(t *testing.T) {
+	engine := NewEngine()
+	tests := []struct{ in, expected string }{
+		{`{{ 1.2 | ceil }}`, "2"},
+		{`{{ 1.2 | ceil | plus: 1 }}`, "3"},
+		{`{{ 1.8 | floor }}`, "1"},
+		{`{{ 1.8 | floor | times: 3 }}`, "3"},
+		{`{{ 183.357 | round }}`, "183"},
+		{`{{ 183.357 | round | minus: 3 }}`, "180"},
+		{`{{ 183.357 | round: 0 | divided_by: 2 }}`, "91"},
+		{`{{ 183.357 | round: 1 }}`, "183.4"},
+		{`{% assign n = 7.5 | floor %}{{ n | plus: 1 }}`, "8"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i+1), func(t *testing.T) {
+			out, err := engine.ParseAndRenderString(test.in, emptyBindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, out, test.in)
+		})
+	}
+}
+
+func TestEngine_ParseAndRenderString_multiline(t *testing.T) {
+	engine := NewEngine()
+	bindings := map[string]interface{}{"x": "a", "n": 2}
+	out, err := engine.ParseAndRenderString("{{ x\n  | upcase\n  | append: \"!\" }}", bindings)
+	require.NoError(t, err)
+	require.Equal(t, "A!", out)
+
+	out, err = engine.ParseAndRenderString("{%\n  if x == \"a\"\n  and n > 1\n%}yes{% endif %}", bindings)
+	require.NoError(t, err)
+	require.Equal(t, "yes", out)
+
+	tpl, err := engine.ParseTemplateLocation([]byte("line 1\n{{ x\n  | undefined_filter }}"), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.RenderString(bindings)
+	require.Error(t, err)
+	var re *RenderError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, 2, re.LineNo)
+}
+
+func TestEngine_RegisterBlock(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterBlock("upcase", func(ctx render.Context) (string, error) {
+		s, err := ctx.InnerString()
+		return strings.ToUpper(s), err
+	})
+	engine.RegisterBlock("verbatim", func(ctx render.Context) (string, error) {
+		return ctx.TagArgs() + ":" + ctx.InnerSource(), nil
+	})
+	bindings := map[string]interface{}{"x": "world"}
+
+	out, err := engine.ParseAndRenderString(`{% upcase %}hello {{ x }}{% if x %}!{% endif %}{% endupcase %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "HELLO WORLD!", out)
+
+	out, err = engine.ParseAndRenderString(`{% verbatim go %}{{ x }}{% if x %}{% verbatim %}y{% endverbatim %}{% else %}z{% endif %}{% endverbatim %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "go:{{ x }}{% if x %}{% verbatim %}y{% endverbatim %}{% else %}z{% endif %}", out)
+
+	out, err = engine.ParseAndRenderString(`{% upcase %}{% verbatim %}{{ x }}{% endverbatim %}{% endupcase %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, ":{{ X }}", out)
+}
+
+func TestEngine_RegisterTag(t *testing.T) {
+	engine := NewEngine()
+	engine.RegisterTag("repeat", func(ctx render.Context) (string, error) {
+		n, err := ctx.EvaluateString(ctx.TagArgs())
+		if err != nil {
+			return "", err
+		}
+		count, ok := n.(int)
+		if !ok {
+			return "", fmt.Errorf("repeat requires an integer; got %v", n)
+		}
+		return strings.Repeat(fmt.Sprint(ctx.Get("s")), count), nil
+	})
+	bindings := map[string]interface{}{"s": "ab", "n": 2}
+
+	out, err := engine.ParseAndRenderString(`{% repeat n | plus: 1 %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "ababab", out)
+
+	tpl, err := engine.ParseTemplateLocation([]byte("line 1\n{% repeat s %}"), "page.html", 1)
+	require.NoError(t, err)
+	_, err = tpl.RenderString(bindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "repeat requires an integer; got ab")
+	var re *RenderError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, "repeat", re.Tag)
+	require.Equal(t, 2, re.LineNo)
+
+	_, err = engine.ParseAndRenderString(`{% repeat n | %}`, bindings)
+	require.Error(t, err)
+}
+
+func TestEngine_RegisterFilter_afterParse(t *testing.T) {
+	engine := NewEngine()
+	tpl, err := engine.ParseString(`{% assign s = "x" | twice %}{{ s }}`)
+	require.NoError(t, err)
+
+	_, err = tpl.RenderString(emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined filter")
+
+	engine.RegisterFilter("twice", func(s string) string { return s + s })
+	out, err := tpl.RenderString(emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "xx", out)
+}
+
+func TestEngine_Clone(t *testing.T) {
+	engine := NewEngine()
+	engine.SetGlobal("site", "Base")
+	clone := engine.Clone()
+	clone.RegisterFilter("twice", func(s string) string { return s + s })
+	clone.RegisterTag("hello", func(render.Context) (string, error) { return "hello", nil })
+	clone.RegisterBlock("wrap", func(ctx render.Context) (string, error) {
+		s, err := ctx.InnerString()
+		return "[" + s + "]", err
+	})
+	clone.SetGlobal("site", "Clone")
+
+	out, err := clone.ParseAndRenderString(`{{ site | twice }} {% hello %} {% wrap %}x{% endwrap %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "CloneClone hello [x]", out)
+
+	out, err = engine.ParseAndRenderString(`{{ site }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "Base", out)
+	_, err = engine.ParseAndRenderString(`{{ site | twice }}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined filter "twice"`)
+	_, err = engine.ParseString(`{% hello %}`)
+	require.Error(t, err)
+	_, err = engine.ParseString(`{% wrap %}x{% endwrap %}`)
+	require.Error(t, err)
+
+	// a block that is defined on the original after cloning isn't defined on the clone
+	engine.RegisterBlock("box", func(ctx render.Context) (string, error) { return "", nil })
+	_, err = clone.ParseString(`{% box %}{% endbox %}`)
+	require.Error(t, err)
+}
+
+func TestEngine_SetGlobal(t *testing.T) {
+	engine := NewEngine()
+	engine.SetGlobal("site", "Global")
+	engine.SetGlobal("title", "Default")
+
+	out, err := engine.ParseAndRenderString(`{{ site }}: {{ title }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "Global: Default", out)
+	out, err = engine.ParseAndRenderString(`{{ site }}: {{ title }}`, map[string]interface{}{"title": "Bound"})
+	require.NoError(t, err)
+	require.Equal(t, "Global: Bound", out)
+
+	tpl, err := engine.ParseString(`{{ site }}{% assign site = "Local" %}|{{ site }}`)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		out, err := tpl.RenderString(map[string]interface{}{})
+		require.NoError(t, err)
+		require.Equal(t, "Global|Local", out)
+	}
+	out, err = engine.ParseAndRenderString(`{{ site }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "Global", out)
+
+	engine.StrictVariables()
+	out, err = engine.ParseAndRenderString(`{% if site %}{{ site }}{% endif %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "Global", out)
+}
+
+func TestEngine_AddTranslations(t *testing.T) {
+	engine := NewEngine()
+	engine.AddTranslations("en", map[string]string{"cart.count": "%{count} items"})
+	engine.AddTranslations("fr", map[string]string{"cart.count": "%{count} articles"})
+	bindings := map[string]interface{}{"cart": []int{1, 2}}
+
+	out, err := engine.ParseAndRenderString(`{{ "cart.count" | t: count: cart.size }}|{{ "cart.total" | t }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "2 items|cart.total", out)
+
+	engine.SetSetting("locale", "fr")
+	out, err = engine.ParseAndRenderString(`{{ "cart.count" | t: count: cart.size }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "2 articles", out)
+}
+
+func TestEngine_StrictVariables(t *testing.T) {
+	engine := NewEngine()
+	out, err := engine.ParseAndRenderString(`{% if missing %}yes{% endif %}{{ missing }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "", out)
+
+	engine.StrictVariables()
+	_, err = engine.ParseAndRenderString(`{% if missing %}yes{% endif %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined variable "missing"`)
+	_, err = engine.ParseAndRenderString(`{% assign x = missing | upcase %}`, emptyBindings)
+	require.Error(t, err)
+
+	out, err = engine.ParseAndRenderString(`{% assign x = "a" %}{{ x }}{% for i in ar %}{{ i }}{% endfor %}`, testBindings)
+	require.NoError(t, err)
+	require.Equal(t, "afirstsecondthird", out)
+}
+
+func TestEngine_DottedKeyFallback(t *testing.T) {
+	bindings := map[string]interface{}{
+		"user.name": "dotted",
+		"user":      map[string]interface{}{"email": "a@example.com"},
+	}
+	engine := NewEngine()
+	out, err := engine.ParseAndRenderString(`{{ user.name }}|{{ user.email }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "|a@example.com", out)
+
+	engine.DottedKeyFallback()
+	out, err = engine.ParseAndRenderString(`{{ user.name }}|{{ user.email }}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "dotted|a@example.com", out)
+}
+
+func TestEngine_LaxFilters(t *testing.T) {
+	engine := NewEngine()
+	_, err := engine.ParseAndRenderString(`{{ "text" | upcas }}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined filter "upcas"; did you mean "upcase"?`)
+
+	engine.LaxFilters()
+	out, err := engine.ParseAndRenderString(`{{ "text" | upcas }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "text", out)
+}
+
+func TestEngine_MaxTemplateSize(t *testing.T) {
+	engine := NewEngine().MaxTemplateSize(12)
+	out, err := engine.ParseAndRenderString(`{{ "text" }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "text", out)
+
+	_, err = engine.ParseAndRenderString(`{{ "texts" }}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "template source is 13 bytes, which exceeds the maximum of 12 bytes")
+
+	_, err = engine.ParseTemplateLocation([]byte(`{{ "texts" }}`), "large.html", 1)
+	require.Error(t, err)
+	require.Equal(t, "large.html", err.Path())
+}
+
+func TestEngine_MaxIterations(t *testing.T) {
+	engine := NewEngine().MaxIterations(100)
+	out, err := engine.ParseAndRenderString(`{% for i in (1..100) %}.{% endfor %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Len(t, out, 100)
+	_, err = engine.ParseAndRenderString(`{% for i in (1..1000000) %}{% endfor %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "loop iterations exceed the limit of 100")
+}
+
+func TestEngine_MaxOutputSize(t *testing.T) {
+	engine := NewEngine().MaxOutputSize(100)
+	src := `{% assign s = "x" %}{% for i in (1..20) %}{% assign s = s | append: s %}{% endfor %}{{ s }}`
+	_, err := engine.ParseAndRenderString(src, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "output exceeds the limit of 100 bytes")
+	out, err := engine.ParseAndRenderString(`{{ "x" | append: "y" }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "xy", out)
+
+	engine.MaxOutputSize(10)
+	_, err = engine.ParseAndRenderString(`{% tablerow i in (1..3) %}{{ i }}{% endtablerow %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "output exceeds the limit of 10 bytes")
+}
+
+func TestEngine_AddJekyllFilters(t *testing.T) {
+	names := []string{
+		"array_to_sentence_string", "cgi_escape", "date_to_long_string", "date_to_rfc822", "date_to_string",
+		"date_to_xmlschema", "jsonify", "normalize_whitespace", "number_of_words", "slugify",
+		"uri_escape", "where_exp", "xml_escape",
+	}
+	requireFiltersAdded(t, names, (*Engine).AddJekyllFilters)
+}
+
+func TestEngine_AddShopifyFilters(t *testing.T) {
+	names := []string{
+		"base64_decode", "base64_encode", "base64_url_safe_decode", "base64_url_safe_encode",
+		"hmac_sha1", "hmac_sha256", "md5", "pluralize", "sha1", "sha256",
+	}
+	requireFiltersAdded(t, names, (*Engine).AddShopifyFilters)
+}
+
+// requireFiltersAdded asserts that each of the named filters is undefined in a new engine, and defined
+// after add is called.
+func requireFiltersAdded(t *testing.T, names []string, add func(*Engine)) {
+	engine := NewEngine()
+	for _, name := range names {
+		_, err := engine.ParseAndRenderString(fmt.Sprintf(`{{ "" | %s }}`, name), emptyBindings)
+		require.Errorf(t, err, name)
+		require.Containsf(t, err.Error(), "undefined filter", name)
+	}
+	add(engine)
+	for _, name := range names {
+		_, err := engine.ParseAndRenderString(fmt.Sprintf(`{{ "" | %s }}`, name), emptyBindings)
+		if err != nil {
+			require.NotContainsf(t, err.Error(), "undefined filter", name)
+		}
+	}
+}
+
 func BenchmarkEngine_Parse(b *testing.B) {
 	engine := NewEngine()
 	buf := new(bytes.Buffer)
@@ -136,3 +635,111 @@ func TestEngine_ParseTemplateAndCache(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, string(result), "Foo, Bar")
 }
+
+func TestEngine_RenderTemplateString(t *testing.T) {
+	engine := NewEngine()
+	for _, name := range []string{"Alice", "Bob"} {
+		out, err := engine.RenderTemplateString(`Hello, {{ name }}!`, Bindings{"name": name})
+		require.NoError(t, err)
+		require.Equal(t, "Hello, "+name+"!", out)
+	}
+	require.Equal(t, 1, engine.templates.len())
+
+	_, err := engine.RenderTemplateString(`{% if %}`, emptyBindings)
+	require.Error(t, err)
+	require.Equal(t, 1, engine.templates.len())
+
+	engine.ClearTemplateCache()
+	require.Equal(t, 0, engine.templates.len())
+
+	// a filter that is redefined after a template is cached applies to it
+	engine.RegisterFilter("shout", strings.ToUpper)
+	out, err := engine.RenderTemplateString(`{{ "a" | shout }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "A", out)
+	engine.RegisterFilter("shout", func(s string) string { return strings.ToUpper(s) + "!" })
+	out, err = engine.RenderTemplateString(`{{ "a" | shout }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "A!", out)
+
+	// changing the delimiters removes the templates that were parsed with the old ones
+	engine.Delims("<<", ">>", "<%", "%>")
+	out, err = engine.RenderTemplateString(`{{ "a" | shout }}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, `{{ "a" | shout }}`, out)
+}
+
+func TestEngine_RenderTemplateString_parseConfig(t *testing.T) {
+	engine := NewEngine()
+	engine.SetFileSystem(mapFileSystem{"snippet.html": "a long included snippet"})
+	source := `{{ "a long template" }}`
+	out, err := engine.RenderTemplateString(source, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "a long template", out)
+	_, err = engine.RenderTemplateString(`{% include "snippet.html" %}`, emptyBindings)
+	require.NoError(t, err)
+
+	// a template that was cached before the limit was set is parsed again
+	engine.MaxTemplateSize(20)
+	require.Equal(t, 0, engine.templates.len())
+	_, err = engine.RenderTemplateString(source, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum of 20 bytes")
+	_, err = engine.RenderTemplateString(`{% include "snippet.html" %}`, emptyBindings)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum of 20 bytes")
+	engine.MaxTemplateSize(0)
+
+	// a tag or block that is redefined after a template is cached applies to it
+	engine.RegisterTag("greet", func(render.Context) (string, error) { return "hello", nil })
+	engine.RegisterBlock("box", func(render.Context) (string, error) { return "[]", nil })
+	out, err = engine.RenderTemplateString(`{% greet %}{% box %}{% endbox %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "hello[]", out)
+	engine.RegisterTag("greet", func(render.Context) (string, error) { return "bye", nil })
+	out, err = engine.RenderTemplateString(`{% greet %}{% box %}{% endbox %}`, emptyBindings)
+	require.NoError(t, err)
+	require.Equal(t, "bye[]", out)
+}
+
+func TestEngine_SetTemplateCacheSize(t *testing.T) {
+	engine := NewEngine().SetTemplateCacheSize(2)
+	render := func(source string) {
+		_, err := engine.RenderTemplateString(source, emptyBindings)
+		require.NoError(t, err)
+	}
+	render("a")
+	render("b")
+	render("a")
+	render("c")
+	require.Equal(t, 2, engine.templates.len())
+	_, ok := engine.templates.get("b")
+	require.False(t, ok, "the least recently used template is evicted")
+	_, ok = engine.templates.get("a")
+	require.True(t, ok)
+	_, ok = engine.templates.get("c")
+	require.True(t, ok)
+
+	engine.SetTemplateCacheSize(0)
+	render("a")
+	require.Equal(t, 0, engine.templates.len())
+}
+
+func BenchmarkEngine_RenderTemplateString(b *testing.B) {
+	source := strings.Repeat(`{% for item in array %}{% if item %}{{ item | upcase }}{% endif %}{% endfor %}`, 20)
+	bindings := Bindings{"array": []string{"a", "b", "c"}}
+	b.Run("ParseAndRenderString", func(b *testing.B) {
+		engine := NewEngine()
+		for i := 0; i < b.N; i++ {
+			_, err := engine.ParseAndRenderString(source, bindings)
+			require.NoError(b, err)
+		}
+	})
+	b.Run("RenderTemplateString", func(b *testing.B) {
+		engine := NewEngine()
+		for i := 0; i < b.N; i++ {
+			_, err := engine.RenderTemplateString(source, bindings)
+			require.NoError(b, err)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package liquid
+
+import (
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/render"
+	"github.com/osteele/liquid/values"
+)
+
+// An Expression is a compiled Liquid expression, such as "a > b" or "page.tags | first".
+// It can be evaluated directly against a variable binding environment, without a template.
+//
+// Use Engine.ParseExpression to create an expression.
+type Expression struct {
+	expr expressions.Expression
+	cfg  *render.Config
+}
+
+// ParseExpression creates a new Expression using the engine configuration.
+//
+// The source is the text that appears within {{ … }}; it can include filters.
+func (e *Engine) ParseExpression(source string) (*Expression, error) {
+	expr, err := expressions.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{expr, &e.cfg}, nil
+}
+
+// Evaluate evaluates the expression within the variable binding environment vars.
+//
+// The filters that are registered with the engine are available, including those registered
+// after the expression is parsed. The engine's StrictVariables and LaxFilters settings also apply.
+//
+// Use the result's Interface method to retrieve the Go value, or its Test method to retrieve
+// its Liquid truthiness.
+func (x *Expression) Evaluate(vars Bindings) (values.Value, error) {
+	ctx := expressions.NewContext(vars, x.cfg.Config.Config)
+	value, err := x.expr.Evaluate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return values.ValueOf(value), nil
+}
@@ -0,0 +1,77 @@
+package liquid
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/osteele/liquid/values"
+	"github.com/stretchr/testify/require"
+)
+
+var expressionTests = []struct {
+	in       string
+	expected interface{}
+}{
+	{`a > b`, true},
+	{`a < b`, false},
+	{`list | first`, "x"},
+	{`list | size`, 3},
+	{`list | reverse | join: "-"`, "z-y-x"},
+	{`(1..3)`, values.NewRange(1, 3)},
+	{`missing`, nil},
+}
+
+var expressionTestBindings = map[string]interface{}{
+	"a":    2,
+	"b":    1.5,
+	"list": []string{"x", "y", "z"},
+}
+
+func TestEngine_ParseExpression(t *testing.T) {
+	engine := NewEngine()
+	for i, test := range expressionTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			expr, err := engine.ParseExpression(test.in)
+			require.NoErrorf(t, err, test.in)
+			value, err := expr.Evaluate(expressionTestBindings)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, value.Interface(), test.in)
+		})
+	}
+
+	_, err := engine.ParseExpression(`a ==`)
+	require.Error(t, err)
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	engine := NewEngine()
+	expr, err := engine.ParseExpression(`n | twice`)
+	require.NoError(t, err)
+
+	// filters are resolved when the expression is evaluated
+	_, err = expr.Evaluate(Bindings{"n": 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined filter")
+
+	engine.RegisterFilter("twice", func(n int) int { return 2 * n })
+	value, err := expr.Evaluate(Bindings{"n": 2})
+	require.NoError(t, err)
+	require.Equal(t, 4, value.Interface())
+	require.True(t, value.Test())
+
+	// a range converts to a Go slice
+	expr, err = engine.ParseExpression(`(1..3)`)
+	require.NoError(t, err)
+	value, err = expr.Evaluate(emptyBindings)
+	require.NoError(t, err)
+	slice, err := values.Convert(value.Interface(), reflect.TypeOf([]interface{}{}))
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{1, 2, 3}, slice)
+
+	engine.StrictVariables()
+	expr, err = engine.ParseExpression(`missing`)
+	require.NoError(t, err)
+	_, err = expr.Evaluate(emptyBindings)
+	require.Error(t, err)
+}
@@ -18,9 +18,9 @@ func makeContainsExpr(e1, e2 func(Context) values.Value) func(Context) values.Va
 	}
 }
 
-func makeFilter(fn valueFn, name string, args []valueFn) valueFn {
+func makeFilter(fn valueFn, name string, params filterParams) valueFn {
 	return func(ctx Context) values.Value {
-		result, err := ctx.ApplyFilter(name, fn, args)
+		result, err := ctx.ApplyFilter(name, fn, params.args, params.keywords...)
 		if err != nil {
 			panic(FilterError{
 				FilterName: name,
@@ -43,3 +43,43 @@ func makeObjectPropertyExpr(objFn func(Context) values.Value, name string) func(
 		return objFn(ctx).PropertyValue(index)
 	}
 }
+
+// makeDottedPropertyExpr is makeObjectPropertyExpr for a chain of properties of a variable,
+// such as a.b.c, whose dotted name is path. If the configuration sets DottedKeyFallback, and
+// the property access evaluates to nil or refers to an undefined variable, the expression
+// evaluates to the binding named path, if there is one.
+func makeDottedPropertyExpr(objFn func(Context) values.Value, name, path string) func(Context) values.Value {
+	propFn := makeObjectPropertyExpr(objFn, name)
+	return func(ctx Context) values.Value {
+		c, ok := ctx.(*context)
+		if !ok || !c.DottedKeyFallback {
+			return propFn(ctx)
+		}
+		value, undefined := evaluateDefined(propFn, ctx)
+		if value != nil && value.Interface() != nil {
+			return value
+		}
+		if v, ok := c.bindings[path]; ok {
+			return values.ValueOf(v)
+		}
+		if undefined != nil {
+			panic(*undefined)
+		}
+		return value
+	}
+}
+
+// evaluateDefined evaluates fn. If fn refers to an undefined variable, it returns the
+// UndefinedVariable error instead of panicking.
+func evaluateDefined(fn func(Context) values.Value, ctx Context) (value values.Value, undefined *UndefinedVariable) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(UndefinedVariable)
+			if !ok {
+				panic(r)
+			}
+			undefined = &e
+		}
+	}()
+	return fn(ctx), nil
+}
@@ -2,10 +2,93 @@ package expressions
 
 // Config holds configuration information for expression interpretation.
 type Config struct {
-	filters map[string]interface{}
+	filters map[string]*filterFunc
+
+	// StrictVariables causes a reference to an undefined variable to be an error.
+	// By default, an undefined variable evaluates to nil.
+	StrictVariables bool
+
+	// DottedKeyFallback causes a property chain such as a.b, if it evaluates to nil or refers
+	// to an undefined variable, to evaluate instead to the variable whose name is the literal
+	// "a.b". Use this for data whose keys contain dots. Property access takes precedence.
+	DottedKeyFallback bool
+
+	// StrictFilters causes an undefined filter to be an error. NewConfig sets this.
+	// If it is false, an undefined filter returns its input unchanged.
+	StrictFilters bool
+
+	// Globals holds variables that are defined in every context, unless the context's own
+	// bindings define a variable with the same name.
+	Globals map[string]interface{}
+
+	// Settings holds application-defined values, such as a locale or a clock, that
+	// context-aware filters read through FilterContext.Config.
+	Settings map[string]interface{}
+
+	// Translations holds the strings that the t filter looks up, by locale and then by key.
+	Translations map[string]map[string]string
+
+	// Warn, if set, receives an UndefinedVariable or UndefinedFilter error for each
+	// reference to an undefined variable or filter that evaluates to nil or to its input
+	// instead of failing, because StrictVariables or StrictFilters isn't set.
+	Warn func(error)
 }
 
 // NewConfig creates a new Config.
 func NewConfig() Config {
-	return Config{}
+	return Config{filters: map[string]*filterFunc{}, StrictFilters: true, Globals: map[string]interface{}{}, Settings: map[string]interface{}{}}
+}
+
+// Clone returns a copy of c whose filters, Globals, Settings, and Translations can be
+// modified without affecting c. The values that these hold are not copied.
+func (c Config) Clone() Config {
+	if c.filters != nil {
+		filters := make(map[string]*filterFunc, len(c.filters))
+		for k, v := range c.filters {
+			filters[k] = v
+		}
+		c.filters = filters
+	}
+	c.Globals = copyMap(c.Globals)
+	c.Settings = copyMap(c.Settings)
+	if c.Translations != nil {
+		translations := make(map[string]map[string]string, len(c.Translations))
+		for locale, table := range c.Translations {
+			t := make(map[string]string, len(table))
+			for k, v := range table {
+				t[k] = v
+			}
+			translations[locale] = t
+		}
+		c.Translations = translations
+	}
+	return c
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// AddTranslations adds translations, by key, for the locale, such as "en" or "fr-CA".
+// A translation can contain placeholders such as %{count}, which the t filter replaces
+// by its keyword arguments.
+func (c *Config) AddTranslations(locale string, translations map[string]string) {
+	if c.Translations == nil {
+		c.Translations = map[string]map[string]string{}
+	}
+	table := c.Translations[locale]
+	if table == nil {
+		table = map[string]string{}
+		c.Translations[locale] = table
+	}
+	for k, v := range translations {
+		table[k] = v
+	}
 }
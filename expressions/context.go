@@ -1,10 +1,10 @@
 package expressions
 
-import "github.com/osteele/liquid/values"
+import "fmt"
 
 // Context is the expression evaluation context. It maps variables names to values.
 type Context interface {
-	ApplyFilter(string, valueFn, []valueFn) (interface{}, error)
+	ApplyFilter(string, valueFn, []valueFn, ...keywordArg) (interface{}, error)
 	// Clone returns a copy with a new variable binding map
 	// (so that copy.Set does effect the source context.)
 	Clone() Context
@@ -30,9 +30,37 @@ func (c *context) Clone() Context {
 	return &context{c.Config, bindings}
 }
 
-// Get looks up a variable value in the expression context.
+// UndefinedVariable is an error that the named variable is not defined.
+type UndefinedVariable string
+
+func (e UndefinedVariable) Error() string {
+	return fmt.Sprintf("undefined variable %q", string(e))
+}
+
+// Get looks up a variable value in the expression context. It looks in the context's
+// bindings, which hold the variables that a template assigns as well as those that it is
+// rendered with, and then in the configuration's Globals.
+//
+// Drops are returned as is. values.ValueOf resolves them lazily, so that a drop
+// that resolves its own properties receives the property accesses.
+//
+// If the configuration sets StrictVariables, Get panics with an UndefinedVariable
+// if the variable is not defined. Expression.Evaluate recovers this as an error.
+// Otherwise it reports the UndefinedVariable to the configuration's Warn function, if any.
 func (c *context) Get(name string) interface{} {
-	return values.ToLiquid(c.bindings[name])
+	value, ok := c.bindings[name]
+	if !ok {
+		value, ok = c.Globals[name]
+	}
+	if !ok {
+		switch {
+		case c.StrictVariables:
+			panic(UndefinedVariable(name))
+		case c.Warn != nil:
+			c.Warn(UndefinedVariable(name))
+		}
+	}
+	return value
 }
 
 // Set sets a variable value in the expression context.
@@ -1,6 +1,11 @@
 // Package expressions is an internal package that parses and evaluates the expression language.
 //
 // This is the language that is used inside Liquid object and tags; e.g. "a.b[c]" in {{ a.b[c] }}, and "pages = site.pages | reverse" in {% assign pages = site.pages | reverse %}.
+//
+// Property and index access bind tighter than filters: "a.b | f" applies f to a.b. The operands
+// of a comparison can't contain filters, so "a | f == c" is a syntax error; use "(a | f) == c".
+// A filter name is an identifier, so "a | f.b" is also a syntax error; use "(a | f).b" to access
+// a property of a filter's result.
 package expressions
 
 import (
@@ -52,10 +57,14 @@ func (e expression) Evaluate(ctx Context) (out interface{}, err error) {
 			switch e := r.(type) {
 			case values.TypeError:
 				err = e
+			case values.PropertyError:
+				err = e
 			case InterpreterError:
 				err = e
 			case UndefinedFilter:
 				err = e
+			case UndefinedVariable:
+				err = e
 			case FilterError:
 				err = e
 			case error:
@@ -23,11 +23,16 @@ func init() {
    cyclefn  func(string) Cycle
    loop     Loop
    loopmods loopModifiers
-   filter_params []valueFn
+   filter_params filterParams
+   pipeline []filterStep
+   // path is the dotted name of an expr such as a.b.c, or empty if the expr isn't a
+   // variable or property chain.
+   path     string
 }
 %type<f> expr rel filtered cond
 %type<filter_params> filter_params
-%type<exprs> exprs expr2
+%type<pipeline> pipeline
+%type<exprs> exprs expr2 indices
 %type<cycle> cycle
 %type<cyclefn> cycle2
 %type<ss> cycle3
@@ -36,19 +41,20 @@ func init() {
 %type<s> string
 %token <val> LITERAL
 %token <name> IDENTIFIER KEYWORD PROPERTY
-%token ASSIGN CYCLE LOOP WHEN
+%token ASSIGN CYCLE LOOP PIPELINE WHEN
 %token EQ NEQ GE LE IN AND OR CONTAINS DOTDOT
 %left '.' '|'
 %left '<' '>'
 %%
 start:
   cond ';' { yylex.(*lexer).val = $1 }
-| ASSIGN IDENTIFIER '=' filtered ';' {
-	yylex.(*lexer).Assignment = Assignment{$2, &expression{$4}}
+| ASSIGN IDENTIFIER indices '=' cond ';' {
+	yylex.(*lexer).Assignment = Assignment{Variable: $2, Indices: $3, ValueFn: &expression{$5}}
 }
 | CYCLE cycle ';' { yylex.(*lexer).Cycle = $2 }
 | LOOP loop ';'   { yylex.(*lexer).Loop = $2 }
 | WHEN exprs ';'  { yylex.(*lexer).When = When{$2} }
+| PIPELINE pipeline ';' { yylex.(*pipelineLexer).Pipeline = $2 }
 ;
 
 cycle: string cycle2 { $$ = $2($1) };
@@ -69,10 +75,20 @@ cycle3:
 | ',' string cycle3 { $$ = append([]string{$2}, $3...) }
 ;
 
+indices:
+  /* empty */ { $$ = nil }
+| indices PROPERTY {
+	index := values.ValueOf($2)
+	$$ = append($1, &expression{func(Context) values.Value { return index }})
+}
+| indices '[' expr ']' { $$ = append($1, &expression{$3}) }
+;
+
 exprs: expr expr2 { $$ = append([]Expression{&expression{$1}}, $2...) } ;
 expr2:
   /* empty */    { $$ = []Expression{} }
 | ',' expr expr2 { $$ = append([]Expression{&expression{$2}}, $3...) }
+| OR expr expr2  { $$ = append([]Expression{&expression{$2}}, $3...) }
 ;
 
 string: LITERAL {
@@ -115,24 +131,45 @@ loop_modifiers: /* empty */ { $$ = loopModifiers{} }
 ;
 
 expr:
-  LITERAL { val := $1; $$ = func(Context) values.Value { return values.ValueOf(val) } }
-| IDENTIFIER { name := $1; $$ = func(ctx Context) values.Value { return values.ValueOf(ctx.Get(name)) } }
-| expr PROPERTY { $$ = makeObjectPropertyExpr($1, $2) }
-| expr '[' expr ']' { $$ = makeIndexExpr($1, $3) }
+  LITERAL { val := values.ValueOf($1); $$ = func(Context) values.Value { return val } }
+| IDENTIFIER {
+	name := $1
+	$$ = func(ctx Context) values.Value { return values.ValueOf(ctx.Get(name)) }
+	$<path>$ = name
+}
+| expr PROPERTY {
+	if $<path>1 == "" {
+		$$ = makeObjectPropertyExpr($1, $2)
+	} else {
+		$<path>$ = $<path>1 + "." + $2
+		$$ = makeDottedPropertyExpr($1, $2, $<path>$)
+	}
+}
+| expr '[' expr ']' { $$ = makeIndexExpr($1, $3); $<path>$ = "" }
 | '(' expr DOTDOT expr ')' { $$ = makeRangeExpr($2, $4) }
 | '(' cond ')' { $$ = $2 }
 ;
 
 filtered:
   expr
-| filtered '|' IDENTIFIER { $$ = makeFilter($1, $3, nil) }
+| filtered '|' IDENTIFIER { $$ = makeFilter($1, $3, filterParams{}) }
 | filtered '|' KEYWORD filter_params { $$ = makeFilter($1, $3, $4) }
 ;
 
 filter_params:
-  expr { $$ = []valueFn{$1} }
+  expr { $$ = filterParams{args: []valueFn{$1}} }
+| KEYWORD expr { $$ = filterParams{keywords: []keywordArg{{$1, $2}}} }
 | filter_params ',' expr
-  { $$ = append($1, $3) }
+  { $1.args = append($1.args, $3); $$ = $1 }
+| filter_params ',' KEYWORD expr
+  { $1.keywords = append($1.keywords, keywordArg{$3, $4}); $$ = $1 }
+
+pipeline:
+  IDENTIFIER { $$ = []filterStep{{$1, filterParams{}}} }
+| KEYWORD filter_params { $$ = []filterStep{{$1, $2}} }
+| pipeline '|' IDENTIFIER { $$ = append($1, filterStep{$3, filterParams{}}) }
+| pipeline '|' KEYWORD filter_params { $$ = append($1, filterStep{$3, $4}) }
+;
 
 rel:
   filtered
@@ -65,6 +65,17 @@ var evaluatorTests = []struct {
 	{`1 == 1.0`, true},
 	{`"a" == "a"`, true},
 	{`"a" == "b"`, false},
+	{`"" == empty`, true},
+	{`empty == ""`, true},
+	{`"a" == empty`, false},
+	{`nil == empty`, false},
+	{`empty == nil`, false},
+	{`nil == "a"`, false},
+	{`nil == blank`, true},
+	{`false == blank`, true},
+	{`" " == blank`, true},
+	{`"a" == blank`, false},
+	{`blank == ""`, true},
 
 	{`1 != 1`, false},
 	{`1 != 2`, true},
@@ -85,6 +96,18 @@ var evaluatorTests = []struct {
 	{`1 > 2`, false},
 	{`2 > 1`, true},
 
+	// mixed int and float
+	{`2 == 2.0`, true},
+	{`2.0 == 2`, true},
+	{`2 == 2.5`, false},
+	{`1 < 1.5`, true},
+	{`1.5 < 1`, false},
+	{`1.5 > 1`, true},
+	{`1 > 1.5`, false},
+	{`2 <= 2.0`, true},
+	{`2.0 >= 2`, true},
+	{`2.5 >= 3`, false},
+
 	{`1 <= 1`, true},
 	{`1 <= 2`, true},
 	{`2 <= 1`, false},
@@ -108,6 +131,12 @@ var evaluatorTests = []struct {
 	{`interface_array contains "first"`, true},
 	{`"foo" contains "missing"`, false},
 	{`nil contains "missing"`, false},
+	{`hash contains "a"`, true},
+	{`hash contains "first"`, false},
+	{`hash contains 1`, false},
+	{`interface_array contains 1`, false},
+	{`n contains 1`, false},
+	{`"1.5 mm" contains 1.5`, true},
 
 	// filters
 	{`"seafood" | length`, 8},
@@ -154,6 +183,110 @@ func TestEvaluateString(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestEvaluateString_filterChain(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("split", strings.Split)
+	cfg.AddFilter("first", func(a []string) string { return a[0] })
+	cfg.AddFilter("join", strings.Join)
+	cfg.AddFilter("upcase", strings.ToUpper)
+	cfg.AddFilter("tag", func(s string, n int) string { return fmt.Sprintf("%s%d", s, n) })
+	ctx := NewContext(map[string]interface{}{}, cfg)
+	tests := []struct {
+		in       string
+		expected interface{}
+	}{
+		{`"a" | tag: 1 | tag: 2 | tag: 3 | tag: 4`, "a1234"},
+		{`"a,b,c" | split: "," | first | upcase`, "A"},
+		{`"a,b,c" | split: "," | join: "-" | upcase | tag: 1`, "A-B-C1"},
+		{`"a" | tag: 1 | split: "" | join: "+" | split: "+" | first`, "a"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			val, err := EvaluateString(test.in, ctx)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, val, test.in)
+		})
+	}
+
+	stmt, err := ParseStatement(AssignStatementSelector, `x = "a,b,c" | split: "," | first | upcase | tag: 2`)
+	require.NoError(t, err)
+	val, err := stmt.Assignment.ValueFn.Evaluate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "A2", val)
+}
+
+func TestEvaluateString_DottedKeyFallback(t *testing.T) {
+	bindings := map[string]interface{}{
+		"a.b":        "literal",
+		"a.b.c":      "deep literal",
+		"site":       map[string]interface{}{"title": "property"},
+		"site.title": "shadowed",
+		"site.url":   "literal url",
+		"list.first": "literal first",
+		"list":       []string{"x"},
+	}
+	tests := []struct {
+		in       string
+		expected interface{}
+	}{
+		{`a.b`, "literal"},
+		{`a.b.c`, "deep literal"},
+		{`a.b | length`, 7},
+		{`site.title`, "property"},
+		{`site.url`, "literal url"},
+		{`list.first`, "x"},
+		{`site.missing`, nil},
+		{`a["b"]`, nil},
+	}
+	cfg := NewConfig()
+	cfg.AddFilter("length", func(s string) int { return len(s) })
+	cfg.DottedKeyFallback = true
+	ctx := NewContext(bindings, cfg)
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			val, err := EvaluateString(test.in, ctx)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, val, test.in)
+		})
+	}
+
+	// without the fallback, a dotted key isn't reachable
+	val, err := EvaluateString(`a.b`, NewContext(bindings, NewConfig()))
+	require.NoError(t, err)
+	require.Nil(t, val)
+
+	// the fallback applies to undefined variables in strict mode
+	cfg.StrictVariables = true
+	ctx = NewContext(bindings, cfg)
+	val, err = EvaluateString(`a.b.c`, ctx)
+	require.NoError(t, err)
+	require.Equal(t, "deep literal", val)
+	_, err = EvaluateString(`undefined.b`, ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined variable "undefined"`)
+}
+
+func TestEvaluateString_Globals(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Globals["site"] = "global site"
+	cfg.Globals["title"] = "global title"
+	ctx := NewContext(map[string]interface{}{"title": "bound title"}, cfg)
+	val, err := EvaluateString(`site`, ctx)
+	require.NoError(t, err)
+	require.Equal(t, "global site", val)
+	val, err = EvaluateString(`title`, ctx)
+	require.NoError(t, err)
+	require.Equal(t, "bound title", val)
+
+	cfg.StrictVariables = true
+	ctx = NewContext(map[string]interface{}{}, cfg)
+	val, err = EvaluateString(`site == "global site"`, ctx)
+	require.NoError(t, err)
+	require.Equal(t, true, val)
+	_, err = EvaluateString(`missing`, ctx)
+	require.Error(t, err)
+}
+
 func TestClosure(t *testing.T) {
 	cfg := NewConfig()
 	ctx := NewContext(map[string]interface{}{"x": 1}, cfg)
@@ -3,6 +3,9 @@ package expressions
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/osteele/liquid/values"
 )
@@ -15,10 +18,22 @@ type InterpreterError string
 func (e InterpreterError) Error() string { return string(e) }
 
 // UndefinedFilter is an error that the named filter is not defined.
-type UndefinedFilter string
+type UndefinedFilter struct {
+	Name string
+	// Suggestions are the names of defined filters that are close to Name.
+	Suggestions []string
+}
 
 func (e UndefinedFilter) Error() string {
-	return fmt.Sprintf("undefined filter %q", string(e))
+	msg := fmt.Sprintf("undefined filter %q", e.Name)
+	if len(e.Suggestions) > 0 {
+		quoted := make([]string, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			quoted[i] = strconv.Quote(s)
+		}
+		msg += "; did you mean " + strings.Join(quoted, " or ") + "?"
+	}
+	return msg
 }
 
 // FilterError is the error returned by a filter when it is applied
@@ -33,23 +48,147 @@ func (e FilterError) Error() string {
 
 type valueFn func(Context) values.Value
 
+// filterParams are the arguments to a filter, such as 1 and key: 2 in {{ x | f: 1, key: 2 }}.
+type filterParams struct {
+	args     []valueFn
+	keywords []keywordArg
+}
+
+type keywordArg struct {
+	name string
+	fn   valueFn
+}
+
+// KeywordArgs holds the keyword arguments to a filter, such as allow_false: true in
+// {{ x | default: 2, allow_false: true }}.
+//
+// A filter whose last parameter has type KeywordArgs receives the keyword arguments
+// in that parameter, as a map that is empty if there are none. Its other parameters
+// receive the positional arguments. It is an error to apply a filter that doesn't
+// have this parameter to keyword arguments.
+type KeywordArgs map[string]interface{}
+
+var keywordArgsType = reflect.TypeOf(KeywordArgs{})
+
+// A FilterContext gives a filter access to the context in which it is applied.
+//
+// A filter whose first parameter has type FilterContext receives the context in
+// that parameter. Its second parameter receives the filter input, and the rest
+// receive the arguments.
+type FilterContext interface {
+	// Config returns the configuration, for access to its Settings.
+	Config() Config
+	// Get returns the value of a variable, from the context's bindings or else from the
+	// configuration's Globals, or nil if it isn't defined. Unlike a reference in the
+	// template, an undefined variable isn't an error if the configuration sets
+	// StrictVariables, and isn't reported to Warn.
+	Get(name string) interface{}
+}
+
+var filterContextType = reflect.TypeOf((*FilterContext)(nil)).Elem()
+
+type filterContext struct{ ctx *context }
+
+func (fc filterContext) Config() Config { return fc.ctx.Config }
+func (fc filterContext) Get(name string) interface{} {
+	if value, ok := fc.ctx.bindings[name]; ok {
+		return value
+	}
+	return fc.ctx.Globals[name]
+}
+
 // AddFilter adds a filter to the filter dictionary.
+//
+// Filters are looked up when an expression is evaluated, not when it is parsed.
+// An expression that was parsed before a filter was added can therefore use the
+// filter, and an undefined filter is an error (or, if StrictFilters is false,
+// passes its input through) only if the expression that uses it is evaluated.
 func (c *Config) AddFilter(name string, fn interface{}) {
 	rf := reflect.ValueOf(fn)
 	switch {
 	case rf.Kind() != reflect.Func:
 		panic(fmt.Errorf("a filter must be a function"))
-	case rf.Type().NumIn() < 1:
+	case rf.Type().NumIn() < 1, rf.Type().NumIn() < 2 && rf.Type().In(0) == filterContextType:
 		panic(fmt.Errorf("a filter function must have at least one input"))
 	case rf.Type().NumOut() < 1 || 2 < rf.Type().NumOut():
 		panic(fmt.Errorf("a filter must be have one or two outputs"))
 		// case rf.Type().Out(1).Implements(…):
 		// 	panic(typeError("a filter's second output must be type error"))
 	}
-	if len(c.filters) == 0 {
-		c.filters = make(map[string]interface{})
+	if c.filters == nil {
+		c.filters = make(map[string]*filterFunc)
 	}
-	c.filters[name] = fn
+	c.filters[name] = newFilterFunc(rf)
+}
+
+// A filterFunc is a filter function, with the information about its type that
+// ApplyFilter uses. It is created once, when the filter is added.
+type filterFunc struct {
+	fn reflect.Value
+	// params is fn's type without its FilterContext and KeywordArgs parameters, if it has
+	// them. The filter input and arguments are converted to its parameter types.
+	params                      reflect.Type
+	takesContext, takesKeywords bool
+}
+
+func newFilterFunc(fn reflect.Value) *filterFunc {
+	ft := fn.Type()
+	f := &filterFunc{fn: fn, params: ft}
+	in := make([]reflect.Type, ft.NumIn())
+	for i := range in {
+		in[i] = ft.In(i)
+	}
+	if in[0] == filterContextType {
+		f.takesContext, in = true, in[1:]
+	}
+	if n := len(in); !ft.IsVariadic() && n > 1 && in[n-1] == keywordArgsType {
+		f.takesKeywords, in = true, in[:n-1]
+	}
+	if f.takesContext || f.takesKeywords {
+		out := make([]reflect.Type, ft.NumOut())
+		for i := range out {
+			out[i] = ft.Out(i)
+		}
+		f.params = reflect.FuncOf(in, out, ft.IsVariadic())
+	}
+	return f
+}
+
+// A filterStep is a filter, and its parameters, within a filter pipeline.
+type filterStep struct {
+	name   string
+	params filterParams
+}
+
+// AddFilterPipeline adds a filter that applies a sequence of filters, such as
+// "strip_html | strip | downcase". The pipeline is parsed once, here. It is an error
+// if the pipeline doesn't parse, or uses a filter that hasn't been added.
+//
+// Arguments within the pipeline are evaluated in the context in which the filter is
+// applied, so they can refer to its variables.
+func (c *Config) AddFilterPipeline(name, pipeline string) error {
+	steps, err := parsePipeline(pipeline)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		if _, ok := c.filters[step.name]; !ok {
+			return UndefinedFilter{step.name, c.similarFilterNames(step.name)}
+		}
+	}
+	c.AddFilter(name, func(fc FilterContext, input interface{}) (interface{}, error) {
+		ctx := fc.(filterContext).ctx
+		for _, step := range steps {
+			value := values.ValueOf(input)
+			out, err := ctx.ApplyFilter(step.name, func(Context) values.Value { return value }, step.params.args, step.params.keywords...)
+			if err != nil {
+				return nil, FilterError{FilterName: step.name, Err: err}
+			}
+			input = out
+		}
+		return input, nil
+	})
+	return nil
 }
 
 var closureType = reflect.TypeOf(closure{})
@@ -59,25 +198,42 @@ func isClosureInterfaceType(t reflect.Type) bool {
 	return closureType.ConvertibleTo(t) && !interfaceType.ConvertibleTo(t)
 }
 
-func (ctx *context) ApplyFilter(name string, receiver valueFn, params []valueFn) (interface{}, error) {
+func (ctx *context) ApplyFilter(name string, receiver valueFn, params []valueFn, keywords ...keywordArg) (interface{}, error) {
 	filter, ok := ctx.filters[name]
 	if !ok {
-		panic(UndefinedFilter(name))
+		if !ctx.StrictFilters {
+			if ctx.Warn != nil {
+				ctx.Warn(UndefinedFilter{name, ctx.similarFilterNames(name)})
+			}
+			return receiver(ctx).Interface(), nil
+		}
+		panic(UndefinedFilter{name, ctx.similarFilterNames(name)})
 	}
-	fr := reflect.ValueOf(filter)
-	args := []interface{}{receiver(ctx).Interface()}
+	var before, after []reflect.Value
+	if filter.takesContext {
+		var fc FilterContext = filterContext{ctx}
+		before = []reflect.Value{reflect.ValueOf(&fc).Elem()}
+	}
+	if filter.takesKeywords {
+		after = []reflect.Value{reflect.ValueOf(ctx.evaluateKeywordArgs(keywords))}
+	} else if len(keywords) > 0 {
+		return nil, fmt.Errorf("filter %q doesn't take keyword arguments", name)
+	}
+	ft := filter.params
+	args := make([]interface{}, 1, 1+len(params))
+	args[0] = receiver(ctx).Interface()
 	for i, param := range params {
-		if i+1 < fr.Type().NumIn() && isClosureInterfaceType(fr.Type().In(i+1)) {
+		if i+1 < ft.NumIn() && isClosureInterfaceType(ft.In(i+1)) {
 			expr, err := Parse(param(ctx).Interface().(string))
 			if err != nil {
-				panic(err)
+				return nil, err
 			}
 			args = append(args, closure{expr, ctx})
 		} else {
 			args = append(args, param(ctx).Interface())
 		}
 	}
-	out, err := values.Call(fr, args)
+	out, err := values.CallWith(filter.fn, ft, before, args, after)
 	if err != nil {
 		if e, ok := err.(*values.CallParityError); ok {
 			err = &values.CallParityError{NumArgs: e.NumArgs - 1, NumParams: e.NumParams - 1}
@@ -91,3 +247,66 @@ func (ctx *context) ApplyFilter(name string, receiver valueFn, params []valueFn)
 		return out, nil
 	}
 }
+
+func (ctx *context) evaluateKeywordArgs(keywords []keywordArg) KeywordArgs {
+	kwargs := make(KeywordArgs, len(keywords))
+	for _, kw := range keywords {
+		kwargs[kw.name] = kw.fn(ctx).Interface()
+	}
+	return kwargs
+}
+
+// maxFilterSuggestions is the maximum number of suggestions in an UndefinedFilter error.
+const maxFilterSuggestions = 3
+
+// similarFilterNames returns the names of defined filters that are a small edit distance from name.
+func (c Config) similarFilterNames(name string) []string {
+	type candidate struct {
+		name string
+		dist int
+	}
+	var candidates []candidate
+	threshold := len(name)/3 + 1
+	for k := range c.filters {
+		if d := editDistance(name, k); d <= threshold {
+			candidates = append(candidates, candidate{k, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		return a.dist < b.dist || (a.dist == b.dist && a.name < b.name)
+	})
+	var names []string
+	for i := 0; i < len(candidates) && i < maxFilterSuggestions; i++ {
+		names = append(names, candidates[i].name)
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = intMin(intMin(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func intMin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
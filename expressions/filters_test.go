@@ -2,6 +2,7 @@ package expressions
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/osteele/liquid/values"
@@ -54,6 +55,68 @@ func TestContext_runFilter(t *testing.T) {
 	require.Contains(t, err.Error(), "given 2")
 	require.Contains(t, err.Error(), "expected 1")
 
+	// keyword arguments
+	cfg.AddFilter("with_keywords", func(a string, b func(string) string, kwargs KeywordArgs) string {
+		return fmt.Sprintf("(%s, %s, %v)", a, b("default"), kwargs["k"])
+	})
+	ctx = NewContext(map[string]interface{}{"x": 10}, cfg)
+	out, err = ctx.ApplyFilter("with_keywords", receiver, []valueFn{}, keywordArg{"k", constant(1)})
+	require.NoError(t, err)
+	require.Equal(t, "(self, default, 1)", out)
+	out, err = ctx.ApplyFilter("with_keywords", receiver, []valueFn{constant("arg")})
+	require.NoError(t, err)
+	require.Equal(t, "(self, arg, <nil>)", out)
+	_, err = ctx.ApplyFilter("with_arg", receiver, []valueFn{constant("arg")}, keywordArg{"k", constant(1)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `filter "with_arg" doesn't take keyword arguments`)
+
+	// filter context
+	cfg.Settings["locale"] = "fr"
+	cfg.AddFilter("localize", func(fc FilterContext, s string, suffix func(string) string) string {
+		return fmt.Sprintf("%s.%v.%v%s", s, fc.Config().Settings["locale"], fc.Get("x"), suffix(""))
+	})
+	ctx = NewContext(map[string]interface{}{"x": 10}, cfg)
+	out, err = ctx.ApplyFilter("localize", receiver, []valueFn{})
+	require.NoError(t, err)
+	require.Equal(t, "self.fr.10", out)
+	out, err = ctx.ApplyFilter("localize", receiver, []valueFn{constant("!")})
+	require.NoError(t, err)
+	require.Equal(t, "self.fr.10!", out)
+	cfg.AddFilter("optional", func(fc FilterContext, s string) string {
+		return fmt.Sprintf("%s.%v.%v", s, fc.Get("site"), fc.Get("missing"))
+	})
+	var warnings []error
+	strict := cfg.Clone()
+	strict.StrictVariables = true
+	strict.Globals["site"] = "global"
+	lenient := cfg.Clone()
+	lenient.Globals["site"] = "global"
+	lenient.Warn = func(err error) { warnings = append(warnings, err) }
+	for _, c := range []Config{strict, lenient} {
+		out, err = NewContext(map[string]interface{}{}, c).ApplyFilter("optional", receiver, []valueFn{})
+		require.NoError(t, err)
+		require.Equal(t, "self.global.<nil>", out)
+		out, err = NewContext(map[string]interface{}{"site": "local"}, c).ApplyFilter("optional", receiver, []valueFn{})
+		require.NoError(t, err)
+		require.Equal(t, "self.local.<nil>", out)
+	}
+	require.Empty(t, warnings)
+	cfg.AddFilter("context_keywords", func(fc FilterContext, s string, kwargs KeywordArgs) string {
+		return fmt.Sprintf("%s.%v.%v", s, fc.Config().Settings["locale"], kwargs["k"])
+	})
+	out, err = ctx.ApplyFilter("context_keywords", receiver, []valueFn{}, keywordArg{"k", constant(1)})
+	require.NoError(t, err)
+	require.Equal(t, "self.fr.1", out)
+	cfg.AddFilter("context_variadic", func(fc FilterContext, s string, args ...int) string {
+		return fmt.Sprintf("%s.%v.%v", s, fc.Get("x"), args)
+	})
+	out, err = ctx.ApplyFilter("context_variadic", receiver, []valueFn{constant(1), constant("2")})
+	require.NoError(t, err)
+	require.Equal(t, "self.10.[1 2]", out)
+	_, err = ctx.ApplyFilter("context_keywords", receiver, []valueFn{constant(1)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "given 1, expected 0")
+
 	// closure
 	cfg.AddFilter("add", func(a, b int) int {
 		return a + b
@@ -70,3 +133,103 @@ func TestContext_runFilter(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "(self, 11)", out)
 }
+
+func TestConfig_AddFilterPipeline(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("strip", strings.TrimSpace)
+	cfg.AddFilter("downcase", strings.ToLower)
+	cfg.AddFilter("append", func(s, suffix string) string { return s + suffix })
+	require.NoError(t, cfg.AddFilterPipeline("clean", "strip | downcase"))
+	require.NoError(t, cfg.AddFilterPipeline("tag", `clean | append: "|" | append: suffix`))
+	ctx := NewContext(map[string]interface{}{"x": "  Some TEXT ", "suffix": "!"}, cfg)
+
+	out, err := EvaluateString("x | clean", ctx)
+	require.NoError(t, err)
+	expected, err := EvaluateString("x | strip | downcase", ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected, out)
+	require.Equal(t, "some text", out)
+
+	out, err = EvaluateString("x | tag | append: '.'", ctx)
+	require.NoError(t, err)
+	require.Equal(t, "some text|!.", out)
+
+	cfg.AddFilter("upcase", strings.ToUpper)
+	cfg.AddFilter("pad", func(s string, kw KeywordArgs) string { return fmt.Sprint(kw["left"]) + s })
+	require.NoError(t, cfg.AddFilterPipeline("shout", "clean | append: (suffix | upcase) | pad: left: '>'"))
+	out, err = EvaluateString("x | shout", ctx)
+	require.NoError(t, err)
+	require.Equal(t, ">some text!", out)
+
+	err = cfg.AddFilterPipeline("bad", "strip | downcas")
+	require.Error(t, err)
+	require.Equal(t, `undefined filter "downcas"; did you mean "downcase"?`, err.Error())
+	err = cfg.AddFilterPipeline("bad", "strip |")
+	require.Error(t, err)
+	err = cfg.AddFilterPipeline("bad", "strip | | downcase")
+	require.Error(t, err)
+	err = cfg.AddFilterPipeline("bad", "x.y | strip")
+	require.Error(t, err)
+}
+
+func TestContext_undefinedFilter(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("upcase", strings.ToUpper)
+	cfg.AddFilter("downcase", strings.ToLower)
+	cfg.AddFilter("upcase_first", strings.Title)
+	ctx := NewContext(map[string]interface{}{"x": "text"}, cfg)
+
+	// strict (the default)
+	_, err := EvaluateString("x | upcas", ctx)
+	require.Error(t, err)
+	require.Equal(t, `undefined filter "upcas"; did you mean "upcase"?`, err.Error())
+	_, err = EvaluateString("x | unknown", ctx)
+	require.Error(t, err)
+	require.Equal(t, `undefined filter "unknown"`, err.Error())
+
+	// lenient
+	cfg.StrictFilters = false
+	ctx = NewContext(map[string]interface{}{"x": "text"}, cfg)
+	out, err := EvaluateString("x | upcas", ctx)
+	require.NoError(t, err)
+	require.Equal(t, "text", out)
+	out, err = EvaluateString("x | upcas: 1, 2 | upcase", ctx)
+	require.NoError(t, err)
+	require.Equal(t, "TEXT", out)
+}
+
+func TestConfig_Clone(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("upcase", strings.ToUpper)
+	cfg.Globals["site"] = "base"
+	cfg.AddTranslations("en", map[string]string{"hello": "Hello"})
+
+	clone := cfg.Clone()
+	clone.AddFilter("downcase", strings.ToLower)
+	clone.Globals["site"] = "clone"
+	clone.Settings["locale"] = "fr"
+	clone.AddTranslations("en", map[string]string{"bye": "Bye"})
+
+	out, err := EvaluateString(`"Text" | upcase | downcase`, NewContext(map[string]interface{}{}, clone))
+	require.NoError(t, err)
+	require.Equal(t, "text", out)
+	_, err = EvaluateString(`"Text" | downcase`, NewContext(map[string]interface{}{}, cfg))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `undefined filter "downcase"`)
+
+	require.Equal(t, "base", cfg.Globals["site"])
+	require.NotContains(t, cfg.Settings, "locale")
+	require.Equal(t, map[string]string{"hello": "Hello"}, cfg.Translations["en"])
+	require.Equal(t, map[string]string{"hello": "Hello", "bye": "Bye"}, clone.Translations["en"])
+}
+
+func TestConfig_similarFilterNames(t *testing.T) {
+	cfg := NewConfig()
+	for _, name := range []string{"upcase", "downcase", "append", "prepend", "date", "size"} {
+		cfg.AddFilter(name, strings.ToUpper)
+	}
+	require.Equal(t, []string{"upcase"}, cfg.similarFilterNames("upcas"))
+	require.Equal(t, []string{"date", "size"}, cfg.similarFilterNames("sate"))
+	require.Equal(t, []string{"append"}, cfg.similarFilterNames("apend"))
+	require.Empty(t, cfg.similarFilterNames("zzz"))
+}
@@ -15,7 +15,29 @@ type parseValue struct {
 	Cycle
 	Loop
 	When
-	val func(Context) values.Value
+	Pipeline []filterStep
+	val      func(Context) values.Value
+}
+
+// A pipelineLexer is a lexer for a filter pipeline, such as "strip | append: suffix".
+// Its first token selects the grammar's pipeline rule.
+type pipelineLexer struct {
+	*lexer
+	started bool
+}
+
+func (lex *pipelineLexer) Lex(out *yySymType) int {
+	if !lex.started {
+		lex.started = true
+		return PIPELINE
+	}
+	return lex.lexer.Lex(out)
+}
+
+// literalKeywords are the identifiers that the lexer reads as literals.
+var literalKeywords = map[string]interface{}{
+	"blank": values.Blank,
+	"empty": values.Empty,
 }
 
 // SyntaxError represents a syntax error. The yacc-generated compiler
@@ -34,6 +56,19 @@ func Parse(source string) (expr Expression, err error) {
 }
 
 func parse(source string) (p *parseValue, err error) {
+	return parseWith(source, false)
+}
+
+// parsePipeline parses a filter pipeline into the filters that it applies, in order.
+func parsePipeline(source string) ([]filterStep, error) {
+	p, err := parseWith(source, true)
+	if err != nil {
+		return nil, err
+	}
+	return p.Pipeline, nil
+}
+
+func parseWith(source string, pipeline bool) (p *parseValue, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			switch e := r.(type) {
@@ -48,7 +83,11 @@ func parse(source string) (p *parseValue, err error) {
 	}()
 	// FIXME hack to recognize EOF
 	lex := newLexer([]byte(source + ";"))
-	n := yyParse(lex)
+	var yylex yyLexer = lex
+	if pipeline {
+		yylex = &pipelineLexer{lexer: lex}
+	}
+	n := yyParse(yylex)
 	if n != 0 {
 		return nil, SyntaxError(fmt.Errorf("syntax error in %q", source).Error())
 	}
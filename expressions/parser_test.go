@@ -19,9 +19,25 @@ var parseTests = []struct {
 	{`a`, 1},
 	{`obj.prop`, 2},
 	{`a | add: b`, 3},
+	{`a | kw: b`, "1 [2] map[]"},
+	{`a | kw: b, c: 3`, "1 [2] map[c:3]"},
+	{`a | kw: b, c: 3, b, d: obj.prop`, "1 [2 2] map[c:3 d:2]"},
+	{`a | kw: b, c: 3, c: 4`, "1 [2] map[c:4]"},
+	{`a | kw`, "1 [] map[]"},
+	{`a | kw_only: opt: true`, "1 map[opt:true]"},
+	{`a | kw: c: 3, b`, "1 [2] map[c:3]"},
 	{`1 == 1`, true},
 	{`1 != 1`, false},
 	{`true and true`, true},
+	{`(a | kw_only).size`, 7},
+	{`(obj.prop | add: a) == 3`, true},
+	{`(list | first).name`, "x"},
+	{`(list | last)["name"]`, "y"},
+	{`(list | last).tags[1]`, "b"},
+	{`(list | last).tags.size`, 2},
+	{`((list | last).tags | first).size`, 1},
+	{`list.first.name`, "x"},
+	{`list[1].tags.first`, "a"},
 }
 
 var parseErrorTests = []struct{ in, expected string }{
@@ -31,16 +47,39 @@ var parseErrorTests = []struct{ in, expected string }{
 	{`%cycle 'a' 'b'`, "syntax error"},
 	{`%loop a in in`, "syntax error"},
 	{`%when a b`, "syntax error"},
+	{`a | f: b, c:`, "syntax error"},
+	{`a | f: b c: 1`, "syntax error"},
+	{`list | first.name`, "syntax error"},
+	{`list | first[0]`, "syntax error"},
+	{`obj.prop | add: a == 3`, "syntax error"},
 }
 
 // Since the parser returns funcs, there's no easy way to test them except evaluation
 func TestParse(t *testing.T) {
 	cfg := NewConfig()
 	cfg.AddFilter("add", func(a, b int) int { return a + b })
+	cfg.AddFilter("kw", func(a int, b, c interface{}, kwargs KeywordArgs) string {
+		args := []interface{}{}
+		for _, arg := range []interface{}{b, c} {
+			if arg != nil {
+				args = append(args, arg)
+			}
+		}
+		return fmt.Sprintf("%v %v %v", a, args, map[string]interface{}(kwargs))
+	})
+	cfg.AddFilter("kw_only", func(a int, kwargs KeywordArgs) string {
+		return fmt.Sprintf("%v %v", a, map[string]interface{}(kwargs))
+	})
+	cfg.AddFilter("first", func(a []interface{}) interface{} { return a[0] })
+	cfg.AddFilter("last", func(a []interface{}) interface{} { return a[len(a)-1] })
 	ctx := NewContext(map[string]interface{}{
 		"a":   1,
 		"b":   2,
 		"obj": map[string]int{"prop": 2},
+		"list": []interface{}{
+			map[string]interface{}{"name": "x"},
+			map[string]interface{}{"name": "y", "tags": []string{"a", "b"}},
+		},
 	}, cfg)
 	for i, test := range parseTests {
 		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
@@ -658,6 +658,13 @@ func (lex *lexer) Lex(out *yySymType) int {
 
 //line scanner.rl:119
 
+	if tok == IDENTIFIER {
+		if v, ok := literalKeywords[out.name]; ok {
+			tok = LITERAL
+			out.val = v
+		}
+	}
+
 	return tok
 }
 
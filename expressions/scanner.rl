@@ -118,6 +118,13 @@ func (lex *lexer) Lex(out *yySymType) int {
 		write exec;
 	}%%
 
+	if tok == IDENTIFIER {
+		if v, ok := literalKeywords[out.name]; ok {
+			tok = LITERAL
+			out.val = v
+		}
+	}
+
 	return tok
 }
 
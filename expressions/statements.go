@@ -17,10 +17,14 @@ type Statement struct{ parseValue }
 // An Assignment is a parse of an {% assign %} statement
 type Assignment struct {
 	Variable string
-	ValueFn  Expression
+	// Indices are the property names and indices of an assignment to an element of the
+	// variable, such as "b" and 0 in {% assign a.b[0] = 1 %}. They're empty in an
+	// assignment to the variable itself.
+	Indices []Expression
+	ValueFn Expression
 }
 
-// A Cycle is a parse of an {% assign %} statement
+// A Cycle is a parse of a {% cycle %} statement
 type Cycle struct {
 	Group  string
 	Values []string
@@ -12,6 +12,25 @@ func TestParseStatement(t *testing.T) {
 	require.Equal(t, "a", stmt.Assignment.Variable)
 	require.Implements(t, (*Expression)(nil), stmt.Assignment.ValueFn)
 
+	stmt, err = ParseStatement(AssignStatementSelector, "a = b contains c and d > 1")
+	require.NoError(t, err)
+	require.Equal(t, "a", stmt.Assignment.Variable)
+	value, err := stmt.Assignment.ValueFn.Evaluate(NewContext(map[string]interface{}{"b": "xcx", "c": "c", "d": 2}, NewConfig()))
+	require.NoError(t, err)
+	require.Equal(t, true, value)
+	require.Empty(t, stmt.Assignment.Indices)
+
+	stmt, err = ParseStatement(AssignStatementSelector, "a.b[i][0] = 1")
+	require.NoError(t, err)
+	require.Equal(t, "a", stmt.Assignment.Variable)
+	require.Len(t, stmt.Assignment.Indices, 3)
+	ctx := NewContext(map[string]interface{}{"i": "c"}, NewConfig())
+	for i, expected := range []interface{}{"b", "c", 0} {
+		value, err := stmt.Assignment.Indices[i].Evaluate(ctx)
+		require.NoError(t, err)
+		require.Equal(t, expected, value)
+	}
+
 	stmt, err = ParseStatement(CycleStatementSelector, "'a', 'b'")
 	require.NoError(t, err)
 	require.Equal(t, "", stmt.Cycle.Group)
@@ -30,7 +30,11 @@ type yySymType struct {
 	cyclefn       func(string) Cycle
 	loop          Loop
 	loopmods      loopModifiers
-	filter_params []valueFn
+	filter_params filterParams
+	pipeline      []filterStep
+	// path is the dotted name of an expr such as a.b.c, or empty if the expr isn't a
+	// variable or property chain.
+	path string
 }
 
 const LITERAL = 57346
@@ -40,16 +44,17 @@ const PROPERTY = 57349
 const ASSIGN = 57350
 const CYCLE = 57351
 const LOOP = 57352
-const WHEN = 57353
-const EQ = 57354
-const NEQ = 57355
-const GE = 57356
-const LE = 57357
-const IN = 57358
-const AND = 57359
-const OR = 57360
-const CONTAINS = 57361
-const DOTDOT = 57362
+const PIPELINE = 57353
+const WHEN = 57354
+const EQ = 57355
+const NEQ = 57356
+const GE = 57357
+const LE = 57358
+const IN = 57359
+const AND = 57360
+const OR = 57361
+const CONTAINS = 57362
+const DOTDOT = 57363
 
 var yyToknames = [...]string{
 	"$end",
@@ -62,6 +67,7 @@ var yyToknames = [...]string{
 	"ASSIGN",
 	"CYCLE",
 	"LOOP",
+	"PIPELINE",
 	"WHEN",
 	"EQ",
 	"NEQ",
@@ -101,77 +107,88 @@ var yyExca = [...]int8{
 
 const yyPrivate = 57344
 
-const yyLast = 106
+const yyLast = 138
 
 var yyAct = [...]int8{
-	9, 47, 42, 18, 8, 14, 15, 23, 10, 11,
-	10, 11, 76, 34, 3, 4, 5, 6, 25, 25,
-	60, 41, 43, 43, 38, 46, 44, 51, 52, 53,
-	54, 55, 56, 57, 58, 12, 25, 12, 39, 62,
-	26, 26, 24, 61, 77, 63, 62, 64, 45, 66,
-	65, 68, 24, 14, 15, 71, 7, 25, 26, 69,
-	70, 13, 27, 28, 31, 32, 72, 73, 75, 33,
-	59, 36, 37, 30, 29, 25, 25, 80, 2, 26,
-	81, 27, 28, 31, 32, 78, 79, 21, 33, 49,
-	50, 35, 30, 29, 16, 19, 48, 26, 26, 1,
-	74, 20, 40, 17, 22, 67,
+	10, 51, 56, 46, 2, 9, 82, 24, 45, 47,
+	19, 11, 12, 96, 38, 11, 12, 47, 39, 3,
+	4, 5, 7, 6, 72, 29, 50, 48, 57, 11,
+	12, 61, 62, 63, 64, 65, 66, 67, 68, 13,
+	11, 12, 58, 13, 71, 15, 16, 73, 30, 29,
+	77, 97, 29, 78, 79, 76, 74, 13, 75, 83,
+	70, 57, 28, 84, 55, 29, 29, 54, 13, 43,
+	86, 49, 30, 99, 88, 30, 87, 53, 89, 90,
+	92, 93, 57, 95, 94, 22, 29, 52, 30, 30,
+	85, 17, 31, 32, 35, 36, 20, 102, 1, 37,
+	69, 91, 103, 34, 33, 29, 21, 15, 16, 30,
+	44, 31, 32, 35, 36, 98, 8, 18, 37, 15,
+	16, 42, 34, 33, 100, 101, 23, 14, 30, 80,
+	81, 25, 40, 41, 59, 60, 26, 27,
 }
 
 var yyPact = [...]int16{
-	6, -1000, 36, 89, 91, 82, 4, -1000, 20, 69,
-	-1000, -1000, 4, -1000, 4, 4, -2, 13, -6, -1000,
-	1, 32, 0, 68, 84, -1000, 4, 4, 4, 4,
-	4, 4, 4, 4, 50, -12, -1000, -1000, 4, -1000,
-	-1000, 91, -1000, 91, -1000, 4, -1000, -1000, 4, -1000,
-	4, 29, 11, 11, 11, 11, 11, 11, 11, 4,
-	-1000, 30, 11, -5, -5, 20, 68, -16, 11, -1000,
-	12, -1000, -1000, -1000, 80, -1000, 4, -1000, -1000, 4,
-	11, 11,
+	11, -32768, 101, 86, 92, 80, 25, 131, -32768, 39,
+	98, -32768, -32768, 25, -32768, 25, 25, -32768, 43, -20,
+	-32768, 1, 54, 0, 58, 41, -32768, 36, 129, -32768,
+	25, 25, 25, 25, 25, 25, 25, 25, 79, 27,
+	-32768, -32768, 17, -32768, -32768, 92, -32768, 92, -32768, 25,
+	-32768, -32768, 25, 25, -32768, 124, -23, 45, 25, -32768,
+	36, 59, 45, 45, 45, 45, 45, 45, 45, 25,
+	-32768, 25, -32768, 25, -12, -12, 39, 45, 58, 58,
+	-32768, 36, 7, 45, -23, -32768, 18, 89, 42, -32768,
+	-32768, 119, -32768, -32768, -23, 45, 25, -32768, -32768, -32768,
+	-32768, 25, 45, 45,
 }
 
-var yyPgo = [...]int8{
-	0, 0, 56, 4, 78, 105, 104, 1, 103, 102,
-	2, 101, 100, 3, 99,
+var yyPgo = [...]uint8{
+	0, 0, 116, 5, 4, 2, 131, 126, 1, 121,
+	117, 110, 3, 106, 101, 10, 98,
 }
 
 var yyR1 = [...]int8{
-	0, 14, 14, 14, 14, 14, 8, 9, 9, 10,
-	10, 6, 7, 7, 13, 11, 12, 12, 12, 1,
-	1, 1, 1, 1, 1, 3, 3, 3, 5, 5,
-	2, 2, 2, 2, 2, 2, 2, 2, 4, 4,
-	4,
+	0, 16, 16, 16, 16, 16, 16, 10, 11, 11,
+	12, 12, 9, 9, 9, 7, 8, 8, 8, 15,
+	13, 14, 14, 14, 1, 1, 1, 1, 1, 1,
+	3, 3, 3, 5, 5, 5, 5, 6, 6, 6,
+	6, 2, 2, 2, 2, 2, 2, 2, 2, 4,
+	4, 4,
 }
 
 var yyR2 = [...]int8{
-	0, 2, 5, 3, 3, 3, 2, 3, 1, 0,
-	3, 2, 0, 3, 1, 4, 0, 2, 3, 1,
-	1, 2, 4, 5, 3, 1, 3, 4, 1, 3,
-	1, 3, 3, 3, 3, 3, 3, 3, 1, 3,
-	3,
+	0, 2, 6, 3, 3, 3, 3, 2, 3, 1,
+	0, 3, 0, 2, 4, 2, 0, 3, 3, 1,
+	4, 0, 2, 3, 1, 1, 2, 4, 5, 3,
+	1, 3, 4, 1, 2, 3, 4, 1, 2, 3,
+	4, 1, 3, 3, 3, 3, 3, 3, 3, 1,
+	3, 3,
 }
 
 var yyChk = [...]int16{
-	-1000, -14, -4, 8, 9, 10, 11, -2, -3, -1,
-	4, 5, 31, 25, 17, 18, 5, -8, -13, 4,
-	-11, 5, -6, -1, 22, 7, 29, 12, 13, 24,
-	23, 14, 15, 19, -1, -4, -2, -2, 26, 25,
-	-9, 27, -10, 28, 25, 16, 25, -7, 28, 5,
-	6, -1, -1, -1, -1, -1, -1, -1, -1, 20,
-	32, -3, -1, -13, -13, -3, -1, -5, -1, 30,
-	-1, 25, -10, -10, -12, -7, 28, 32, 5, 6,
-	-1, -1,
+	-32768, -16, -4, 8, 9, 10, 12, 11, -2, -3,
+	-1, 4, 5, 32, 26, 18, 19, 5, -10, -15,
+	4, -13, 5, -7, -1, -6, 5, 6, 23, 7,
+	30, 13, 14, 25, 24, 15, 16, 20, -1, -4,
+	-2, -2, -9, 26, -11, 28, -12, 29, 26, 17,
+	26, -8, 29, 19, 26, 23, -5, -1, 6, 5,
+	6, -1, -1, -1, -1, -1, -1, -1, -1, 21,
+	33, 27, 7, 30, -15, -15, -3, -1, -1, -1,
+	5, 6, 29, -1, -5, 31, -1, -4, -1, -12,
+	-12, -14, -8, -8, -5, -1, 6, 33, 26, 31,
+	5, 6, -1, -1,
 }
 
 var yyDef = [...]int8{
-	0, -2, 0, 0, 0, 0, 0, 38, 30, 25,
-	19, 20, 0, 1, 0, 0, 0, 0, 9, 14,
-	0, 0, 0, 12, 0, 21, 0, 0, 0, 0,
-	0, 0, 0, 0, 25, 0, 39, 40, 0, 3,
-	6, 0, 8, 0, 4, 0, 5, 11, 0, 26,
-	0, 0, 31, 32, 33, 34, 35, 36, 37, 0,
-	24, 0, 25, 9, 9, 16, 12, 27, 28, 22,
-	0, 2, 7, 10, 15, 13, 0, 23, 17, 0,
-	29, 18,
+	0, -2, 0, 0, 0, 0, 0, 0, 49, 41,
+	30, 24, 25, 0, 1, 0, 0, 12, 0, 10,
+	19, 0, 0, 0, 16, 0, 37, 0, 0, 26,
+	0, 0, 0, 0, 0, 0, 0, 0, 30, 0,
+	50, 51, 0, 3, 7, 0, 9, 0, 4, 0,
+	5, 15, 0, 0, 6, 0, 38, 33, 0, 31,
+	0, 0, 42, 43, 44, 45, 46, 47, 48, 0,
+	29, 0, 13, 0, 10, 10, 21, 30, 16, 16,
+	39, 0, 0, 34, 32, 27, 0, 0, 0, 8,
+	11, 20, 17, 18, 40, 35, 0, 28, 2, 14,
+	22, 0, 36, 23,
 }
 
 var yyTok1 = [...]int8{
@@ -179,20 +196,20 @@ var yyTok1 = [...]int8{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	31, 32, 3, 3, 28, 3, 21, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 27, 25,
-	23, 26, 24, 3, 3, 3, 3, 3, 3, 3,
+	32, 33, 3, 3, 29, 3, 22, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 28, 26,
+	24, 27, 25, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 29, 3, 30, 3, 3, 3, 3, 3, 3,
+	3, 30, 3, 31, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 22,
+	3, 3, 3, 3, 23,
 }
 
 var yyTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
-	12, 13, 14, 15, 16, 17, 18, 19, 20,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 }
 
 var yyTok3 = [...]int8{
@@ -238,7 +255,7 @@ func yyNewParser() yyParser {
 	return &yyParserImpl{}
 }
 
-const yyFlag = -1000
+const yyFlag = -32768
 
 func yyTokname(c int) string {
 	if c >= 1 && c-1 < len(yyToknames) {
@@ -538,87 +555,118 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:45
+//line expressions.y:50
 		{
 			yylex.(*lexer).val = yyDollar[1].f
 		}
 	case 2:
-		yyDollar = yyS[yypt-5 : yypt+1]
-//line expressions.y:46
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line expressions.y:51
 		{
-			yylex.(*lexer).Assignment = Assignment{yyDollar[2].name, &expression{yyDollar[4].f}}
+			yylex.(*lexer).Assignment = Assignment{Variable: yyDollar[2].name, Indices: yyDollar[3].exprs, ValueFn: &expression{yyDollar[5].f}}
 		}
 	case 3:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:49
+//line expressions.y:54
 		{
 			yylex.(*lexer).Cycle = yyDollar[2].cycle
 		}
 	case 4:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:50
+//line expressions.y:55
 		{
 			yylex.(*lexer).Loop = yyDollar[2].loop
 		}
 	case 5:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:51
+//line expressions.y:56
 		{
 			yylex.(*lexer).When = When{yyDollar[2].exprs}
 		}
 	case 6:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line expressions.y:57
+		{
+			yylex.(*pipelineLexer).Pipeline = yyDollar[2].pipeline
+		}
+	case 7:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:54
+//line expressions.y:60
 		{
 			yyVAL.cycle = yyDollar[2].cyclefn(yyDollar[1].s)
 		}
-	case 7:
+	case 8:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:57
+//line expressions.y:63
 		{
 			h, t := yyDollar[2].s, yyDollar[3].ss
 			yyVAL.cyclefn = func(g string) Cycle { return Cycle{g, append([]string{h}, t...)} }
 		}
-	case 8:
+	case 9:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:61
+//line expressions.y:67
 		{
 			vals := yyDollar[1].ss
 			yyVAL.cyclefn = func(h string) Cycle { return Cycle{Values: append([]string{h}, vals...)} }
 		}
-	case 9:
+	case 10:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line expressions.y:68
+//line expressions.y:74
 		{
 			yyVAL.ss = []string{}
 		}
-	case 10:
+	case 11:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:69
+//line expressions.y:75
 		{
 			yyVAL.ss = append([]string{yyDollar[2].s}, yyDollar[3].ss...)
 		}
-	case 11:
+	case 12:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line expressions.y:79
+		{
+			yyVAL.exprs = nil
+		}
+	case 13:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:72
+//line expressions.y:80
+		{
+			index := values.ValueOf(yyDollar[2].name)
+			yyVAL.exprs = append(yyDollar[1].exprs, &expression{func(Context) values.Value { return index }})
+		}
+	case 14:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line expressions.y:84
+		{
+			yyVAL.exprs = append(yyDollar[1].exprs, &expression{yyDollar[3].f})
+		}
+	case 15:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line expressions.y:87
 		{
 			yyVAL.exprs = append([]Expression{&expression{yyDollar[1].f}}, yyDollar[2].exprs...)
 		}
-	case 12:
+	case 16:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line expressions.y:74
+//line expressions.y:89
 		{
 			yyVAL.exprs = []Expression{}
 		}
-	case 13:
+	case 17:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:75
+//line expressions.y:90
 		{
 			yyVAL.exprs = append([]Expression{&expression{yyDollar[2].f}}, yyDollar[3].exprs...)
 		}
-	case 14:
+	case 18:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line expressions.y:91
+		{
+			yyVAL.exprs = append([]Expression{&expression{yyDollar[2].f}}, yyDollar[3].exprs...)
+		}
+	case 19:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:78
+//line expressions.y:94
 		{
 			s, ok := yyDollar[1].val.(string)
 			if !ok {
@@ -626,22 +674,22 @@ yydefault:
 			}
 			yyVAL.s = s
 		}
-	case 15:
+	case 20:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line expressions.y:86
+//line expressions.y:102
 		{
 			name, expr, mods := yyDollar[1].name, yyDollar[3].f, yyDollar[4].loopmods
 			yyVAL.loop = Loop{name, &expression{expr}, mods}
 		}
-	case 16:
+	case 21:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line expressions.y:92
+//line expressions.y:108
 		{
 			yyVAL.loopmods = loopModifiers{}
 		}
-	case 17:
+	case 22:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:93
+//line expressions.y:109
 		{
 			switch yyDollar[2].name {
 			case "reversed":
@@ -651,9 +699,9 @@ yydefault:
 			}
 			yyVAL.loopmods = yyDollar[1].loopmods
 		}
-	case 18:
+	case 23:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:102
+//line expressions.y:118
 		{
 			switch yyDollar[2].name {
 			case "cols":
@@ -667,71 +715,116 @@ yydefault:
 			}
 			yyVAL.loopmods = yyDollar[1].loopmods
 		}
-	case 19:
+	case 24:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:118
+//line expressions.y:134
 		{
-			val := yyDollar[1].val
-			yyVAL.f = func(Context) values.Value { return values.ValueOf(val) }
+			val := values.ValueOf(yyDollar[1].val)
+			yyVAL.f = func(Context) values.Value { return val }
 		}
-	case 20:
+	case 25:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:119
+//line expressions.y:135
 		{
 			name := yyDollar[1].name
 			yyVAL.f = func(ctx Context) values.Value { return values.ValueOf(ctx.Get(name)) }
+			yyVAL.path = name
 		}
-	case 21:
+	case 26:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line expressions.y:120
+//line expressions.y:140
 		{
-			yyVAL.f = makeObjectPropertyExpr(yyDollar[1].f, yyDollar[2].name)
+			if yyDollar[1].path == "" {
+				yyVAL.f = makeObjectPropertyExpr(yyDollar[1].f, yyDollar[2].name)
+			} else {
+				yyVAL.path = yyDollar[1].path + "." + yyDollar[2].name
+				yyVAL.f = makeDottedPropertyExpr(yyDollar[1].f, yyDollar[2].name, yyVAL.path)
+			}
 		}
-	case 22:
+	case 27:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line expressions.y:121
+//line expressions.y:148
 		{
 			yyVAL.f = makeIndexExpr(yyDollar[1].f, yyDollar[3].f)
+			yyVAL.path = ""
 		}
-	case 23:
+	case 28:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line expressions.y:122
+//line expressions.y:149
 		{
 			yyVAL.f = makeRangeExpr(yyDollar[2].f, yyDollar[4].f)
 		}
-	case 24:
+	case 29:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:123
+//line expressions.y:150
 		{
 			yyVAL.f = yyDollar[2].f
 		}
-	case 26:
+	case 31:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:128
+//line expressions.y:155
 		{
-			yyVAL.f = makeFilter(yyDollar[1].f, yyDollar[3].name, nil)
+			yyVAL.f = makeFilter(yyDollar[1].f, yyDollar[3].name, filterParams{})
 		}
-	case 27:
+	case 32:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line expressions.y:129
+//line expressions.y:156
 		{
 			yyVAL.f = makeFilter(yyDollar[1].f, yyDollar[3].name, yyDollar[4].filter_params)
 		}
-	case 28:
+	case 33:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line expressions.y:133
+//line expressions.y:160
 		{
-			yyVAL.filter_params = []valueFn{yyDollar[1].f}
+			yyVAL.filter_params = filterParams{args: []valueFn{yyDollar[1].f}}
 		}
-	case 29:
+	case 34:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line expressions.y:161
+		{
+			yyVAL.filter_params = filterParams{keywords: []keywordArg{{yyDollar[1].name, yyDollar[2].f}}}
+		}
+	case 35:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:135
+//line expressions.y:163
 		{
-			yyVAL.filter_params = append(yyDollar[1].filter_params, yyDollar[3].f)
+			yyDollar[1].filter_params.args = append(yyDollar[1].filter_params.args, yyDollar[3].f)
+			yyVAL.filter_params = yyDollar[1].filter_params
 		}
-	case 31:
+	case 36:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line expressions.y:165
+		{
+			yyDollar[1].filter_params.keywords = append(yyDollar[1].filter_params.keywords, keywordArg{yyDollar[3].name, yyDollar[4].f})
+			yyVAL.filter_params = yyDollar[1].filter_params
+		}
+	case 37:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line expressions.y:168
+		{
+			yyVAL.pipeline = []filterStep{{yyDollar[1].name, filterParams{}}}
+		}
+	case 38:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line expressions.y:169
+		{
+			yyVAL.pipeline = []filterStep{{yyDollar[1].name, yyDollar[2].filter_params}}
+		}
+	case 39:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:139
+//line expressions.y:170
+		{
+			yyVAL.pipeline = append(yyDollar[1].pipeline, filterStep{yyDollar[3].name, filterParams{}})
+		}
+	case 40:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line expressions.y:171
+		{
+			yyVAL.pipeline = append(yyDollar[1].pipeline, filterStep{yyDollar[3].name, yyDollar[4].filter_params})
+		}
+	case 42:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line expressions.y:176
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -739,9 +832,9 @@ yydefault:
 				return values.ValueOf(a.Equal(b))
 			}
 		}
-	case 32:
+	case 43:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:146
+//line expressions.y:183
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -749,9 +842,9 @@ yydefault:
 				return values.ValueOf(!a.Equal(b))
 			}
 		}
-	case 33:
+	case 44:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:153
+//line expressions.y:190
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -759,9 +852,9 @@ yydefault:
 				return values.ValueOf(b.Less(a))
 			}
 		}
-	case 34:
+	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:160
+//line expressions.y:197
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -769,9 +862,9 @@ yydefault:
 				return values.ValueOf(a.Less(b))
 			}
 		}
-	case 35:
+	case 46:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:167
+//line expressions.y:204
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -779,9 +872,9 @@ yydefault:
 				return values.ValueOf(b.Less(a) || a.Equal(b))
 			}
 		}
-	case 36:
+	case 47:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:174
+//line expressions.y:211
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
@@ -789,24 +882,24 @@ yydefault:
 				return values.ValueOf(a.Less(b) || a.Equal(b))
 			}
 		}
-	case 37:
+	case 48:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:181
+//line expressions.y:218
 		{
 			yyVAL.f = makeContainsExpr(yyDollar[1].f, yyDollar[3].f)
 		}
-	case 39:
+	case 50:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:186
+//line expressions.y:223
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
 				return values.ValueOf(fa(ctx).Test() && fb(ctx).Test())
 			}
 		}
-	case 40:
+	case 51:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line expressions.y:192
+//line expressions.y:229
 		{
 			fa, fb := yyDollar[1].f, yyDollar[3].f
 			yyVAL.f = func(ctx Context) values.Value {
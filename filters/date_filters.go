@@ -0,0 +1,112 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/osteele/tuesday"
+)
+
+// goLayoutRE matches the elements of the Go reference time that identify a date
+// format as a Go layout.
+var goLayoutRE = regexp.MustCompile(`2006|Jan|Mon|15`)
+
+// dateFilter formats t with a strftime-style format such as "%Y-%m-%d", or with a Go
+// layout such as "2006-01-02". A format that contains a % is a strftime format. A
+// format without one is a Go layout if it contains "2006", "Jan", "Mon", or "15"
+// from the reference time Mon Jan 2 15:04:05 MST 2006; otherwise it's literal text.
+func dateFilter(t time.Time, format func(string) string) (string, error) {
+	f := format("%a, %b %d, %y")
+	if !strings.Contains(f, "%") && goLayoutRE.MatchString(f) {
+		return t.Format(f), nil
+	}
+	return tuesday.Strftime(f, t)
+}
+
+// strftimeLayouts maps strftime conversion characters to Go time layout elements.
+var strftimeLayouts = map[string]string{
+	"a":  "Mon",
+	"A":  "Monday",
+	"b":  "Jan",
+	"B":  "January",
+	"d":  "02",
+	"-d": "2",
+	"e":  "_2",
+	"H":  "15",
+	"I":  "03",
+	"-I": "3",
+	"j":  "002",
+	"L":  "000",
+	"m":  "01",
+	"-m": "1",
+	"M":  "04",
+	"p":  "PM",
+	"S":  "05",
+	"y":  "06",
+	"Y":  "2006",
+	"z":  "-0700",
+	"Z":  "MST",
+	"%":  "%",
+}
+
+// parseDateFilter parses s according to a strftime-style format.
+// The optional timezone names the location of times that don't specify one.
+func parseDateFilter(s, format string, timezone func(string) string) (time.Time, error) {
+	layout, err := strftimeToLayout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := time.Local
+	if name := timezone(""); name != "" {
+		loc, err = time.LoadLocation(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't parse %q with format %q", s, format)
+	}
+	return t, nil
+}
+
+// strftimeToLayout converts a strftime format to a Go time layout.
+//
+// Go layouts have no escape mechanism, so literal text in the format may not
+// contain digits or letters, with the exception of the ISO 8601 separator "T".
+func strftimeToLayout(format string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			if (unicode.IsDigit(rune(c)) || unicode.IsLetter(rune(c))) && c != 'T' {
+				return "", fmt.Errorf("unsupported literal %q in date format %q", c, format)
+			}
+			b.WriteByte(c)
+			continue
+		}
+		directive := format[i+1:]
+		switch {
+		case strings.HasPrefix(directive, "-") && len(directive) >= 2:
+			directive = directive[:2]
+		case len(directive) >= 1:
+			directive = directive[:1]
+		}
+		layout, ok := strftimeLayouts[directive]
+		if !ok {
+			return "", fmt.Errorf("unsupported directive %q in date format %q", "%"+directive, format)
+		}
+		if directive == "L" {
+			// fractional seconds must follow a period in a Go layout
+			if !strings.HasSuffix(b.String(), ".") {
+				return "", fmt.Errorf("%%L must follow a period in date format %q", format)
+			}
+		}
+		b.WriteString(layout)
+		i += len(directive)
+	}
+	return b.String(), nil
+}
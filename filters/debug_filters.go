@@ -0,0 +1,133 @@
+package filters
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// debugFilter formats value as a Go-like literal, with the types of its scalars and of
+// the elements of its maps and slices, for example
+//
+//	map[string]interface {}{"a": []interface {}{int(1), "x", nil}}
+//
+// A map, slice, or pointer that contains itself is formatted as <cycle> where it recurs.
+//
+// debugFilter implements the inspect filter and its alias, debug.
+func debugFilter(value interface{}) string {
+	var b strings.Builder
+	f := debugFormatter{&b, map[debugCycleKey]bool{}}
+	f.format(reflect.ValueOf(value), true)
+	return b.String()
+}
+
+// A debugCycleKey identifies a map, slice, or pointer that is being formatted.
+type debugCycleKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+type debugFormatter struct {
+	b      *strings.Builder
+	active map[debugCycleKey]bool
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// format writes v. If typed is true, and v is a scalar whose type can't be inferred from its
+// container, it is annotated with its type.
+func (f debugFormatter) format(v reflect.Value, typed bool) {
+	if !v.IsValid() {
+		f.b.WriteString("nil")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		f.format(v.Elem(), true)
+		return
+	case reflect.Map, reflect.Ptr, reflect.Slice:
+		if v.IsNil() {
+			fmt.Fprintf(f.b, "(%s)(nil)", v.Type())
+			return
+		}
+		if v.Kind() != reflect.Slice || v.Len() > 0 {
+			key := debugCycleKey{v.Type(), v.Pointer()}
+			if f.active[key] {
+				f.b.WriteString("<cycle>")
+				return
+			}
+			f.active[key] = true
+			defer delete(f.active, key)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		fmt.Fprintf(f.b, "%s{", v.Type())
+		typedKeys, typedValues := v.Type().Key().Kind() == reflect.Interface, v.Type().Elem().Kind() == reflect.Interface
+		entries := make([][2]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			entries = append(entries, [2]string{f.sub(k, typedKeys), f.sub(v.MapIndex(k), typedValues)})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i][0] < entries[j][0] })
+		for i, e := range entries {
+			if i > 0 {
+				f.b.WriteString(", ")
+			}
+			f.b.WriteString(e[0] + ": " + e[1])
+		}
+		f.b.WriteString("}")
+	case reflect.Array, reflect.Slice:
+		fmt.Fprintf(f.b, "%s{", v.Type())
+		typedElems := v.Type().Elem().Kind() == reflect.Interface
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				f.b.WriteString(", ")
+			}
+			f.format(v.Index(i), typedElems)
+		}
+		f.b.WriteString("}")
+	case reflect.Ptr:
+		f.b.WriteString("&")
+		f.format(v.Elem(), false)
+	case reflect.Struct:
+		if v.CanInterface() && v.Type().Implements(stringerType) {
+			fmt.Fprintf(f.b, "%s(%q)", v.Type(), v.Interface().(fmt.Stringer).String())
+			return
+		}
+		fmt.Fprintf(f.b, "%s{", v.Type())
+		sep := ""
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			fmt.Fprintf(f.b, "%s%s: ", sep, field.Name)
+			f.format(v.Field(i), field.Type.Kind() == reflect.Interface)
+			sep = ", "
+		}
+		f.b.WriteString("}")
+	case reflect.String:
+		// a string literal's type is string, so only other string types are annotated
+		if typed && v.Type() != reflect.TypeOf("") {
+			fmt.Fprintf(f.b, "%s(%q)", v.Type(), v.String())
+		} else {
+			fmt.Fprintf(f.b, "%q", v.String())
+		}
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		fmt.Fprintf(f.b, "%s", v.Type())
+	default:
+		if typed {
+			fmt.Fprintf(f.b, "%s(%v)", v.Type(), v)
+		} else {
+			fmt.Fprintf(f.b, "%v", v)
+		}
+	}
+}
+
+// sub returns the formatting of v.
+func (f debugFormatter) sub(v reflect.Value, typed bool) string {
+	var b strings.Builder
+	debugFormatter{&b, f.active}.format(v, typed)
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package filters
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/values"
+)
+
+// AddJekyllFilters defines the filters that Jekyll adds to the standard Liquid filters.
+//
+// markdownify, smartify, and the sass filters are not defined, since they require a
+// Markdown or Sass processor. A client can define these with its own implementation.
+func AddJekyllFilters(fd FilterDictionary) {
+	// array filters
+	fd.AddFilter("array_to_sentence_string", arrayToSentenceStringFilter)
+	fd.AddFilter("where_exp", whereExpFilter)
+
+	// date filters
+	fd.AddFilter("date_to_long_string", func(t time.Time, typ, style func(string) string) string {
+		return formatJekyllDate(t, "January", typ(""), style(""))
+	})
+	fd.AddFilter("date_to_rfc822", func(t time.Time) string {
+		return t.Format("Mon, 02 Jan 2006 15:04:05 -0700")
+	})
+	fd.AddFilter("date_to_string", func(t time.Time, typ, style func(string) string) string {
+		return formatJekyllDate(t, "Jan", typ(""), style(""))
+	})
+	fd.AddFilter("date_to_xmlschema", func(t time.Time) string {
+		return t.Format("2006-01-02T15:04:05-07:00")
+	})
+
+	// string filters
+	fd.AddFilter("cgi_escape", url.QueryEscape)
+	fd.AddFilter("jsonify", func(a interface{}) (string, error) {
+		result, err := values.ToJSON(a)
+		return string(result), err
+	})
+	fd.AddFilter("normalize_whitespace", func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	})
+	fd.AddFilter("number_of_words", func(s string) int {
+		return len(strings.Fields(s))
+	})
+	fd.AddFilter("slugify", slugifyFilter)
+	fd.AddFilter("uri_escape", uriEscapeFilter)
+	fd.AddFilter("xml_escape", xmlEscaper.Replace)
+}
+
+var xmlEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;", `'`, "&#39;")
+
+func arrayToSentenceStringFilter(a []interface{}, connector func(string) string) string {
+	conn := connector("and")
+	ss := make([]string, len(a))
+	for i, v := range a {
+		ss[i] = fmt.Sprint(v)
+	}
+	switch len(ss) {
+	case 0:
+		return ""
+	case 1:
+		return ss[0]
+	case 2:
+		return ss[0] + " " + conn + " " + ss[1]
+	default:
+		return strings.Join(ss[:len(ss)-1], ", ") + ", " + conn + " " + ss[len(ss)-1]
+	}
+}
+
+// formatJekyllDate implements date_to_string and date_to_long_string. month is the
+// Go layout element for the month name.
+func formatJekyllDate(t time.Time, month, typ, style string) string {
+	if typ != "ordinal" {
+		return t.Format("02 " + month + " 2006")
+	}
+	day := ordinalize(t.Day())
+	if style == "US" {
+		return t.Format(month) + " " + day + ", " + t.Format("2006")
+	}
+	return day + " " + t.Format(month+" 2006")
+}
+
+func ordinalize(n int) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// whereExpFilter selects the elements of a for which expr is truthy, with each element bound
+// to name.
+func whereExpFilter(a []interface{}, name string, expr expressions.Closure) ([]interface{}, error) {
+	test := elementPredicate(name, expr)
+	result := []interface{}{}
+	for _, item := range a {
+		ok, err := test(item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+var (
+	slugifyRawRE     = regexp.MustCompile(`\s+`)
+	slugifyDefaultRE = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+	slugifyPrettyRE  = regexp.MustCompile(`[^\p{L}\p{N}._~!$&'()+,;=@]+`)
+)
+
+// slugifyFilter implements the Jekyll slugify filter. mode is "none", "raw", "default",
+// or "pretty".
+func slugifyFilter(s string, mode func(string) string) (string, error) {
+	var re *regexp.Regexp
+	switch m := mode("default"); m {
+	case "none":
+		return s, nil
+	case "raw":
+		re = slugifyRawRE
+	case "default":
+		re = slugifyDefaultRE
+	case "pretty":
+		re = slugifyPrettyRE
+	default:
+		return "", fmt.Errorf("unknown slugify mode %q", m)
+	}
+	return strings.Trim(re.ReplaceAllString(strings.ToLower(s), "-"), "-"), nil
+}
+
+// uriReserved is the characters that are reserved in a URI, and the unreserved
+// punctuation.
+const uriReserved = "-._~:/?#[]@!$&'()*+,;="
+
+// uriEscapeFilter percent-encodes the characters of s that are neither unreserved
+// nor reserved in a URI.
+func uriEscapeFilter(s string) string {
+	return percentEncode(s, uriReserved)
+}
+
+// percentEncode percent-encodes the bytes of s other than ASCII letters, digits, and
+// the characters in keep. A % is encoded, so that encoded input is encoded again.
+func percentEncode(s, keep string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', strings.IndexByte(keep, c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package filters
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/osteele/liquid/expressions"
+	"github.com/stretchr/testify/require"
+)
+
+var jekyllFilterTests = []struct {
+	in       string
+	expected interface{}
+}{
+	// array filters
+	{`empty_array | array_to_sentence_string`, ""},
+	{`"a,b" | split: "," | array_to_sentence_string`, "a and b"},
+	{`fruits | array_to_sentence_string`, "apples, oranges, peaches, and plums"},
+	{`fruits | array_to_sentence_string: "or"`, "apples, oranges, peaches, or plums"},
+	{`posts | where_exp: "post", "post.category == 'news'" | map: "title" | join`, "a c"},
+	{`posts | where_exp: "post", "post.title contains 'd'" | map: "title" | join`, "d"},
+	{`posts | where_exp: "post", "post.missing" | size`, 0},
+	{`posts | where_exp: "post", "post.category == news_category" | map: "title" | join`, "a c"},
+	{`posts | where_exp: "post", "(post.title | upcase) == 'D'" | map: "title" | join`, "d"},
+
+	// date filters
+	{`time | date_to_xmlschema`, "2008-11-07T13:07:54-08:00"},
+	{`time | date_to_rfc822`, "Fri, 07 Nov 2008 13:07:54 -0800"},
+	{`time | date_to_string`, "07 Nov 2008"},
+	{`time | date_to_string: "ordinal"`, "7th Nov 2008"},
+	{`time | date_to_string: "ordinal", "US"`, "Nov 7th, 2008"},
+	{`time | date_to_long_string`, "07 November 2008"},
+	{`time | date_to_long_string: "ordinal", "US"`, "November 7th, 2008"},
+
+	// string filters
+	{`"foo, bar; baz?" | cgi_escape`, "foo%2C+bar%3B+baz%3F"},
+	{`map | jsonify`, `{"a":1}`},
+	{`whitespace | normalize_whitespace`, "a b c"},
+	{`"This is a sentence." | number_of_words`, 4},
+	{`"The _config.yml file" | slugify`, "the-config-yml-file"},
+	{`"The _config.yml file" | slugify: "pretty"`, "the-_config.yml-file"},
+	{`"The _config.yml file" | slugify: "raw"`, "the-_config.yml-file"},
+	{`"The _config.yml file" | slugify: "none"`, "The _config.yml file"},
+	{`"Héllo, Wörld!" | slugify`, "héllo-wörld"},
+	{`"foo, bar \baz?" | uri_escape`, "foo,%20bar%20%5Cbaz?"},
+	{`html | xml_escape`, "&lt;p&gt;&quot;Tom &amp; Jerry&#39;s&quot;&lt;/p&gt;"},
+}
+
+var jekyllFilterTestBindings = map[string]interface{}{
+	"html":          `<p>"Tom & Jerry's"</p>`,
+	"news_category": "news",
+	"whitespace":    "  a \n b\tc ",
+	"posts": []map[string]interface{}{
+		{"title": "a", "category": "news"},
+		{"title": "b", "category": "sports"},
+		{"title": "c", "category": "news"},
+		{"title": "d"},
+	},
+}
+
+func TestJekyllFilters(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	bindings := map[string]interface{}{"time": time.Date(2008, 11, 7, 13, 7, 54, 0, loc)}
+	for k, v := range filterTestBindings {
+		bindings[k] = v
+	}
+	for k, v := range jekyllFilterTestBindings {
+		bindings[k] = v
+	}
+
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	AddJekyllFilters(&cfg)
+	context := expressions.NewContext(bindings, cfg)
+
+	for i, test := range jekyllFilterTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			actual, err := expressions.EvaluateString(test.in, context)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, actual, test.in)
+		})
+	}
+
+	_, err = expressions.EvaluateString(`"a" | slugify: "ascii"`, context)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown slugify mode")
+}
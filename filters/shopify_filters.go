@@ -0,0 +1,167 @@
+package filters
+
+import (
+	"crypto/hmac"
+	"crypto/md5"  // nolint: gosec
+	"crypto/sha1" // nolint: gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/osteele/liquid/expressions"
+)
+
+// AddShopifyFilters defines the filters that Shopify adds to the standard Liquid filters,
+// for use in storefront themes.
+//
+// The money filters read the "money_format" and "currency" settings from the Config.
+// These default to "${{amount}}" and "USD".
+func AddShopifyFilters(fd FilterDictionary) {
+	// money filters
+	fd.AddFilter("money", func(fc expressions.FilterContext, cents interface{}) (string, error) {
+		return moneyFilter(fc, cents, false)
+	})
+	fd.AddFilter("money_with_currency", func(fc expressions.FilterContext, cents interface{}) (string, error) {
+		return moneyFilter(fc, cents, true)
+	})
+
+	// string filters
+	fd.AddFilter("base64_decode", func(s string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		return string(b), err
+	})
+	fd.AddFilter("base64_encode", func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	})
+	fd.AddFilter("base64_url_safe_decode", func(s string) (string, error) {
+		b, err := base64.URLEncoding.DecodeString(s)
+		return string(b), err
+	})
+	fd.AddFilter("base64_url_safe_encode", func(s string) string {
+		return base64.URLEncoding.EncodeToString([]byte(s))
+	})
+	fd.AddFilter("handle", handleizeFilter)
+	fd.AddFilter("handleize", handleizeFilter)
+	fd.AddFilter("hmac_sha1", func(s, key string) string {
+		return hashHex(hmac.New(sha1.New, []byte(key)), s)
+	})
+	fd.AddFilter("hmac_sha256", func(s, key string) string {
+		return hashHex(hmac.New(sha256.New, []byte(key)), s)
+	})
+	fd.AddFilter("md5", func(s string) string {
+		return hashHex(md5.New(), s) // nolint: gosec
+	})
+	fd.AddFilter("pluralize", func(n float64, singular, plural string) string {
+		if n == 1 {
+			return singular
+		}
+		return plural
+	})
+	fd.AddFilter("sha1", func(s string) string {
+		return hashHex(sha1.New(), s) // nolint: gosec
+	})
+	fd.AddFilter("sha256", func(s string) string {
+		return hashHex(sha256.New(), s)
+	})
+	// url_escape leaves the characters that are reserved in a URI, for use in a path;
+	// url_param_escape also encodes &, for use in a query parameter value. Unlike
+	// url_encode, these encode a space as %20. Both encode %, so that encoded input is
+	// encoded again.
+	fd.AddFilter("url_escape", func(s string) string {
+		return percentEncode(s, uriReserved)
+	})
+	fd.AddFilter("url_param_escape", func(s string) string {
+		return percentEncode(s, strings.ReplaceAll(uriReserved, "&", ""))
+	})
+}
+
+// handleTransliterations maps lowercase accented Latin letters to ASCII.
+var handleTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ß': "ss", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y",
+}
+
+// handleizeFilter converts s to a Shopify handle: it lowercases s, transliterates
+// accented Latin letters to ASCII, and replaces each run of other characters that
+// aren't ASCII letters or digits by a single hyphen, omitting these at either end.
+func handleizeFilter(s string) string {
+	var b strings.Builder
+	sep := false
+	for _, c := range strings.ToLower(s) {
+		t, ok := handleTransliterations[c]
+		switch {
+		case ok:
+		case 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+			t = string(c)
+		default:
+			sep = true
+			continue
+		}
+		if sep && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		sep = false
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+var moneyAmountRE = regexp.MustCompile(`{{\s*amount\s*}}`)
+
+// moneyFilter formats an amount in cents with the money_format setting, followed by the
+// currency setting if withCurrency is true.
+func moneyFilter(fc expressions.FilterContext, cents interface{}, withCurrency bool) (string, error) {
+	n, err := toNumber(cents)
+	if err != nil {
+		return "", err
+	}
+	format, currency := "${{amount}}", "USD"
+	settings := fc.Config().Settings
+	if s, ok := settings["money_format"].(string); ok {
+		format = s
+	}
+	if s, ok := settings["currency"].(string); ok {
+		currency = s
+	}
+	amount := toFloat(n) / 100
+	s := moneyAmountRE.ReplaceAllLiteralString(format, formatMoneyAmount(math.Abs(amount)))
+	if math.Round(amount*100) < 0 {
+		s = "-" + s
+	}
+	if withCurrency {
+		s += " " + currency
+	}
+	return s, nil
+}
+
+// formatMoneyAmount formats the non-negative f with two decimal places and comma
+// thousands separators.
+func formatMoneyAmount(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	whole, frac := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String() + frac
+}
+
+func hashHex(h hash.Hash, s string) string {
+	h.Write([]byte(s)) // nolint: errcheck
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,86 @@
+package filters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/osteele/liquid/expressions"
+	"github.com/stretchr/testify/require"
+)
+
+var shopifyFilterTests = []struct {
+	in       string
+	expected interface{}
+}{
+	{`"one two three" | base64_encode`, "b25lIHR3byB0aHJlZQ=="},
+	{`"b25lIHR3byB0aHJlZQ==" | base64_decode`, "one two three"},
+	{`"abcdefghijklmnopqrstuvwxyz ABCDEFGHIJKLMNOPQRSTUVWXYZ 1234567890 !@#$%^&*()-=_+/?.:;[]{}\|" | base64_url_safe_encode`,
+		"YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXogQUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVogMTIzNDU2Nzg5MCAhQCMkJV4mKigpLT1fKy8_Ljo7W117fVx8"},
+	{`"YWJjICsvPz4" | append: "=" | base64_url_safe_decode`, "abc +/?>"},
+	{`"Polina" | md5`, "71d0ea493b78a6a33e7a68ae74254017"},
+	{`"Polina" | sha1`, "f429155c2cac473dc23ff4a0a353bd7fb3c1cfe7"},
+	{`"Polina" | sha256`, "2e78aebdaa6e03cd6f6567338006cccb33b2a13d254ef3c4c8b00701e0d8a325"},
+	{`"Polina" | hmac_sha1: "polina"`, "6ecd2202d6fa827de31938a37152e995c964578b"},
+	{`"Polina" | hmac_sha256: "polina"`, "561bc3f2e3cf8fac939b68bd2b7014dd986f5463befe779922fe50eea90523da"},
+	{`1 | pluralize: "item", "items"`, "item"},
+	{`3 | pluralize: "item", "items"`, "items"},
+	{`0 | pluralize: "item", "items"`, "items"},
+	{`"100% Cotton T-Shirt" | handleize`, "100-cotton-t-shirt"},
+	{`"Hello, World!" | handleize`, "hello-world"},
+	{`"a -- b__c" | handle`, "a-b-c"},
+	{`"  --Sale!--  " | handleize`, "sale"},
+	{`"Crème Brûlée Straße" | handleize`, "creme-brulee-strasse"},
+	{`"日本 Tea" | handleize`, "tea"},
+	{`145 | money`, "$1.45"},
+	{`0 | money`, "$0.00"},
+	{`123456789 | money`, "$1,234,567.89"},
+	{`-100000 | money`, "-$1,000.00"},
+	{`"2500" | money_with_currency`, "$25.00 USD"},
+	{`"<hello> & <shopify>" | url_escape`, "%3Chello%3E%20&%20%3Cshopify%3E"},
+	{`"<hello> & <shopify>" | url_param_escape`, "%3Chello%3E%20%26%20%3Cshopify%3E"},
+	{`"a b/c&d=e" | url_escape`, "a%20b/c&d=e"},
+	{`"a b/c&d=e" | url_param_escape`, "a%20b/c%26d=e"},
+	{`"a b/c&d=e" | url_encode`, "a+b%2Fc%26d%3De"},
+	{`"a%20b" | url_escape`, "a%2520b"},
+	{`"a%20b" | url_param_escape`, "a%2520b"},
+	{`"café" | url_escape`, "caf%C3%A9"},
+}
+
+func TestShopifyFilters(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	AddShopifyFilters(&cfg)
+	context := expressions.NewContext(filterTestBindings, cfg)
+
+	for i, test := range shopifyFilterTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			actual, err := expressions.EvaluateString(test.in, context)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, actual, test.in)
+		})
+	}
+
+	_, err := expressions.EvaluateString(`"not base64!" | base64_decode`, context)
+	require.Error(t, err)
+}
+
+func TestShopifyFilters_money(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddShopifyFilters(&cfg)
+	cfg.Settings["money_format"] = "{{ amount }} €"
+	cfg.Settings["currency"] = "EUR"
+	context := expressions.NewContext(map[string]interface{}{"price": 123456}, cfg)
+
+	actual, err := expressions.EvaluateString(`price | money`, context)
+	require.NoError(t, err)
+	require.Equal(t, "1,234.56 €", actual)
+	actual, err = expressions.EvaluateString(`price | money_with_currency`, context)
+	require.NoError(t, err)
+	require.Equal(t, "1,234.56 € EUR", actual)
+	actual, err = expressions.EvaluateString(`0 | money`, context)
+	require.NoError(t, err)
+	require.Equal(t, "0.00 €", actual)
+
+	_, err = expressions.EvaluateString(`"free" | money`, context)
+	require.Error(t, err)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/osteele/liquid/values"
 )
 
+// sortFilter sorts the elements of array, or the maps in array by the value of their key
+// property. The sort is stable, so that elements that compare equal keep their order.
 func sortFilter(array []interface{}, key interface{}) []interface{} {
 	result := make([]interface{}, len(array))
 	copy(result, array)
@@ -20,19 +22,21 @@ func sortFilter(array []interface{}, key interface{}) []interface{} {
 	return result
 }
 
+// sortNaturalFilter sorts array case-insensitively, like sortFilter. This sort is also
+// stable.
 func sortNaturalFilter(array []interface{}, key interface{}) interface{} {
 	result := make([]interface{}, len(array))
 	copy(result, array)
 	switch {
 	case reflect.ValueOf(array).Len() == 0:
 	case key != nil:
-		sort.Sort(keySortable{result, func(m interface{}) string {
+		sort.Stable(keySortable{result, func(m interface{}) string {
 			rv := reflect.ValueOf(m)
 			if rv.Kind() != reflect.Map {
 				return ""
 			}
 			ev := rv.MapIndex(reflect.ValueOf(key))
-			if ev.CanInterface() {
+			if ev.IsValid() && ev.CanInterface() {
 				if s, ok := ev.Interface().(string); ok {
 					return strings.ToLower(s)
 				}
@@ -40,7 +44,7 @@ func sortNaturalFilter(array []interface{}, key interface{}) interface{} {
 			return ""
 		}})
 	case reflect.TypeOf(array[0]).Kind() == reflect.String:
-		sort.Sort(keySortable{result, func(s interface{}) string {
+		sort.Stable(keySortable{result, func(s interface{}) string {
 			return strings.ToUpper(s.(string))
 		}})
 	}
@@ -2,19 +2,28 @@
 package filters
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"html"
 	"math"
+	"net/mail"
 	"net/url"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
-	"time"
 	"unicode"
+	"unicode/utf8"
 
+	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/values"
-	"github.com/osteele/tuesday"
+)
+
+var (
+	stripHTMLRE = regexp.MustCompile(`<.*?>`)
+	// newlineRE matches a Unix, Windows, or classic Mac OS line break.
+	newlineRE = regexp.MustCompile(`\r\n|\r|\n`)
 )
 
 // A FilterDictionary holds filters.
@@ -25,17 +34,15 @@ type FilterDictionary interface {
 // AddStandardFilters defines the standard Liquid filters.
 func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 	// value filters
-	fd.AddFilter("default", func(value, defaultValue interface{}) interface{} {
-		if value == nil || value == false || values.IsEmpty(value) {
-			value = defaultValue
-		}
-		return value
-	})
+	fd.AddFilter("default", defaultFilter)
 	fd.AddFilter("json", func(a interface{}) interface{} {
 		result, _ := json.Marshal(a)
 		return result
 	})
 
+	// map filters
+	fd.AddFilter("merge", mergeFilter)
+
 	// array filters
 	fd.AddFilter("compact", func(a []interface{}) (result []interface{}) {
 		for _, item := range a {
@@ -49,6 +56,13 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 		result = make([]interface{}, 0, len(a)+len(b))
 		return append(append(result, a...), b...)
 	})
+	fd.AddFilter("chunk_by", chunkByFilter)
+	fd.AddFilter("drop_while", func(a []interface{}, name string, expr expressions.Closure) ([]interface{}, error) {
+		n, err := leadingRunLength(a, name, expr)
+		return a[n:], err
+	})
+	fd.AddFilter("group_by", groupByFilter)
+	fd.AddFilter("in_groups_of", inGroupsOfFilter)
 	fd.AddFilter("join", joinFilter)
 	fd.AddFilter("map", func(a []interface{}, key string) (result []interface{}) {
 		keyValue := values.ValueOf(key)
@@ -58,63 +72,62 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 		}
 		return result
 	})
+	fd.AddFilter("pop", func(a []interface{}, n func(int) int) []interface{} {
+		return sliceFilter(a, 0, len(a)-clampCount(n(1), len(a)))
+	})
+	fd.AddFilter("push", func(a []interface{}, item interface{}) []interface{} {
+		result := make([]interface{}, 0, len(a)+1)
+		return append(append(result, a...), item)
+	})
+	fd.AddFilter("reject", whereFilter(true))
 	fd.AddFilter("reverse", reverseFilter)
+	fd.AddFilter("shift", func(a []interface{}, n func(int) int) []interface{} {
+		return sliceFilter(a, clampCount(n(1), len(a)), len(a))
+	})
 	fd.AddFilter("sort", sortFilter)
 	// https://shopify.github.io/liquid/ does not demonstrate first and last as filters,
 	// but https://help.shopify.com/themes/liquid/filters/array-filters does
-	fd.AddFilter("first", func(a []interface{}) interface{} {
-		if len(a) == 0 {
-			return nil
-		}
-		return a[0]
-	})
-	fd.AddFilter("last", func(a []interface{}) interface{} {
-		if len(a) == 0 {
-			return nil
-		}
-		return a[len(a)-1]
+	fd.AddFilter("first", firstLastFilter("first"))
+	fd.AddFilter("last", firstLastFilter("last"))
+	fd.AddFilter("take_while", func(a []interface{}, name string, expr expressions.Closure) ([]interface{}, error) {
+		n, err := leadingRunLength(a, name, expr)
+		return a[:n], err
 	})
+	fd.AddFilter("to_csv", toCSVFilter)
 	fd.AddFilter("uniq", uniqFilter)
+	fd.AddFilter("where", whereFilter(false))
+	fd.AddFilter("unshift", func(a []interface{}, item interface{}) []interface{} {
+		result := make([]interface{}, 0, len(a)+1)
+		return append(append(result, item), a...)
+	})
 
 	// date filters
-	fd.AddFilter("date", func(t time.Time, format func(string) string) (string, error) {
-		f := format("%a, %b %d, %y")
-		return tuesday.Strftime(f, t)
-	})
+	fd.AddFilter("date", dateFilter)
+	fd.AddFilter("parse_date", parseDateFilter)
 
 	// number filters
-	fd.AddFilter("abs", math.Abs)
+	fd.AddFilter("abs", absFilter)
+	fd.AddFilter("at_least", boundFilter(false))
+	fd.AddFilter("at_most", boundFilter(true))
 	fd.AddFilter("ceil", func(a float64) int {
 		return int(math.Ceil(a))
 	})
 	fd.AddFilter("floor", func(a float64) int {
 		return int(math.Floor(a))
 	})
-	fd.AddFilter("modulo", math.Mod)
-	fd.AddFilter("minus", func(a, b float64) float64 {
-		return a - b
-	})
-	fd.AddFilter("plus", func(a, b float64) float64 {
-		return a + b
-	})
-	fd.AddFilter("times", func(a, b float64) float64 {
-		return a * b
-	})
-	fd.AddFilter("divided_by", func(a float64, b interface{}) interface{} {
-		switch q := b.(type) {
-		case int, int16, int32, int64:
-			return int(a) / q.(int)
-		case float32, float64:
-			return a / b.(float64)
-		default:
-			return nil
-		}
-	})
-	fd.AddFilter("round", func(n float64, places func(int) int) float64 {
-		pl := places(0)
-		exp := math.Pow10(pl)
-		return math.Floor(n*exp+0.5) / exp
-	})
+	fd.AddFilter("modulo", moduloFilter)
+	fd.AddFilter("minus", arithmeticFilter(
+		func(a, b int) int { return a - b },
+		func(a, b float64) float64 { return a - b }))
+	fd.AddFilter("plus", arithmeticFilter(
+		func(a, b int) int { return a + b },
+		func(a, b float64) float64 { return a + b }))
+	fd.AddFilter("times", arithmeticFilter(
+		func(a, b int) int { return a * b },
+		func(a, b float64) float64 { return a * b }))
+	fd.AddFilter("divided_by", dividedByFilter)
+	fd.AddFilter("round", roundFilter)
+	fd.AddFilter("percent_of", percentOfFilter)
 
 	// sequence filters
 	fd.AddFilter("size", values.Length)
@@ -123,6 +136,8 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 	fd.AddFilter("append", func(s, suffix string) string {
 		return s + suffix
 	})
+	fd.AddFilter("camelcase", camelCaseFilter(true))
+	fd.AddFilter("camelize", camelCaseFilter(true))
 	fd.AddFilter("capitalize", func(s, suffix string) string {
 		if len(s) == 0 {
 			return s
@@ -132,13 +147,24 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 	fd.AddFilter("downcase", func(s, suffix string) string {
 		return strings.ToLower(s)
 	})
-	fd.AddFilter("escape", html.EscapeString)
-	fd.AddFilter("escape_once", func(s, suffix string) string {
-		return html.EscapeString(html.UnescapeString(s))
+	fd.AddFilter("highlight", highlightFilter)
+	fd.AddFilter("is_email", func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
 	})
+	fd.AddFilter("is_url", func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	})
+	fd.AddFilter("lower_camelcase", camelCaseFilter(false))
+	fd.AddFilter("escape", html.EscapeString)
+	fd.AddFilter("escape_once", escapeOnceFilter)
+	fd.AddFilter("decode_entities", html.UnescapeString)
+	fd.AddFilter("encode_entities", encodeEntitiesFilter)
 	fd.AddFilter("newline_to_br", func(s string) string {
-		return strings.Replace(s, "\n", "<br />", -1)
+		return newlineRE.ReplaceAllString(s, "<br />$0")
 	})
+	fd.AddFilter("normalize_url", normalizeURLFilter)
 	fd.AddFilter("prepend", func(s, prefix string) string {
 		return prefix + s
 	})
@@ -154,6 +180,7 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 	fd.AddFilter("replace_first", func(s, old, new string) string {
 		return strings.Replace(s, old, new, 1)
 	})
+	fd.AddFilter("reading_time", readingTimeFilter)
 	fd.AddFilter("sort_natural", sortNaturalFilter)
 	fd.AddFilter("slice", func(s string, start int, length func(int) int) string {
 		ss := []rune(s)
@@ -168,37 +195,26 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 		return string(ss[start:end])
 	})
 	fd.AddFilter("split", splitFilter)
+	fd.AddFilter("path_segments", pathSegmentsFilter)
 	fd.AddFilter("strip_html", func(s string) string {
 		// TODO this probably isn't sufficient
-		return regexp.MustCompile(`<.*?>`).ReplaceAllString(s, "")
+		return stripHTMLRE.ReplaceAllString(s, "")
 	})
 	fd.AddFilter("strip_newlines", func(s string) string {
-		return strings.Replace(s, "\n", "", -1)
+		return newlineRE.ReplaceAllLiteralString(s, "")
+	})
+	fd.AddFilter("strip", func(s string) string {
+		return strings.TrimFunc(s, unicode.IsSpace)
 	})
-	fd.AddFilter("strip", strings.TrimSpace)
 	fd.AddFilter("lstrip", func(s string) string {
 		return strings.TrimLeftFunc(s, unicode.IsSpace)
 	})
 	fd.AddFilter("rstrip", func(s string) string {
 		return strings.TrimRightFunc(s, unicode.IsSpace)
 	})
-	fd.AddFilter("truncate", func(s string, length func(int) int, ellipsis func(string) string) string {
-		n := length(50)
-		el := ellipsis("...")
-		// runes aren't bytes; don't use slice
-		re := regexp.MustCompile(fmt.Sprintf(`^(.{%d})..{%d,}`, n-len(el), len(el)))
-		return re.ReplaceAllString(s, `$1`+el)
-	})
-	fd.AddFilter("truncatewords", func(s string, length func(int) int, ellipsis func(string) string) string {
-		el := ellipsis("...")
-		n := length(15)
-		re := regexp.MustCompile(fmt.Sprintf(`^(?:\s*\S+){%d}`, n))
-		m := re.FindString(s)
-		if m == "" {
-			return s
-		}
-		return m + el
-	})
+	fd.AddFilter("t", translateFilter)
+	fd.AddFilter("truncate", truncateFilter)
+	fd.AddFilter("truncatewords", truncateWordsFilter)
 	fd.AddFilter("upcase", func(s, suffix string) string {
 		return strings.ToUpper(s)
 	})
@@ -206,20 +222,219 @@ func AddStandardFilters(fd FilterDictionary) { // nolint: gocyclo
 	fd.AddFilter("url_decode", url.QueryUnescape)
 
 	// debugging filters
-	// inspect is from Jekyll
-	fd.AddFilter("inspect", func(value interface{}) string {
-		s, err := json.Marshal(value)
-		if err != nil {
-			return fmt.Sprintf("%#v", value)
-		}
-		return string(s)
-	})
+	// inspect is from Jekyll; debug is an alias
+	fd.AddFilter("inspect", debugFilter)
+	fd.AddFilter("debug", debugFilter)
 	fd.AddFilter("type", func(value interface{}) string {
 		return fmt.Sprintf("%T", value)
 	})
 }
 
-func joinFilter(a []interface{}, sep func(string) string) interface{} {
+// defaultFilter returns defaultValue if value is nil, false, or an empty string, array,
+// or map; and otherwise value. With allow_false: true, false is returned unchanged.
+func defaultFilter(value, defaultValue interface{}, kwargs expressions.KeywordArgs) interface{} {
+	if value == false {
+		if allow, ok := kwargs["allow_false"]; ok && values.ValueOf(allow).Test() {
+			return value
+		}
+	}
+	if value == nil || value == false || values.IsEmpty(value) {
+		return defaultValue
+	}
+	return value
+}
+
+// chunkByFilter splits a into runs of consecutive elements whose property values are equal,
+// and returns the runs as arrays. Unlike group_by, elements with the same value that aren't
+// adjacent are in different runs.
+func chunkByFilter(a []interface{}, property string) []interface{} {
+	var (
+		key    = values.ValueOf(property)
+		result = []interface{}{}
+		prev   values.Value
+	)
+	for i, item := range a {
+		v := values.ValueOf(item).PropertyValue(key)
+		if i == 0 || !v.Equal(prev) {
+			result = append(result, []interface{}{})
+		}
+		result[len(result)-1] = append(result[len(result)-1].([]interface{}), item)
+		prev = v
+	}
+	return result
+}
+
+// firstLastFilter returns the first or last filter, according to key. Like the first and
+// last properties, these return the first or last element of an array, or character of a
+// string; or nil if it is empty.
+//
+// With a count, they instead return an array of the first or last count elements, or a
+// string of the first or last count characters. These are all the elements or characters
+// if there are fewer than count.
+func firstLastFilter(key string) func(interface{}, ...int) (interface{}, error) {
+	keyValue := values.ValueOf(key)
+	return func(v interface{}, count ...int) (interface{}, error) {
+		switch {
+		case len(count) > 1:
+			return nil, fmt.Errorf("wrong number of arguments (given %d, expected 0 or 1)", len(count))
+		case len(count) == 1 && count[0] < 0:
+			return nil, fmt.Errorf("%s requires a non-negative count; got %d", key, count[0])
+		}
+		v = values.ToLiquid(v)
+		switch s := v.(type) {
+		case nil:
+			return nil, nil
+		case string:
+			if len(count) > 0 {
+				r := []rune(s)
+				i, j := firstLastRange(key, len(r), count[0])
+				return string(r[i:j]), nil
+			}
+			return values.ValueOf(v).PropertyValue(keyValue).Interface(), nil
+		}
+		if reflect.TypeOf(v).Kind() == reflect.Map {
+			return nil, nil
+		}
+		a, err := values.Convert(v, reflect.TypeOf([]interface{}{}))
+		if err != nil {
+			return nil, err
+		}
+		if len(count) > 0 {
+			elements := a.([]interface{})
+			i, j := firstLastRange(key, len(elements), count[0])
+			return elements[i:j], nil
+		}
+		return values.ValueOf(a).PropertyValue(keyValue).Interface(), nil
+	}
+}
+
+// firstLastRange returns the bounds of the first or last n of length elements, according
+// to key.
+func firstLastRange(key string, length, n int) (int, int) {
+	n = clampCount(n, length)
+	if key == "first" {
+		return 0, n
+	}
+	return length - n, length
+}
+
+// elementPredicate returns a function that evaluates expr, in the template's context with
+// name bound to an element, and reports whether the result is truthy.
+func elementPredicate(name string, expr expressions.Closure) func(item interface{}) (bool, error) {
+	return func(item interface{}) (bool, error) {
+		v, err := expr.Bind(name, item).Evaluate()
+		if err != nil {
+			return false, err
+		}
+		return v != nil && v != false, nil
+	}
+}
+
+// leadingRunLength returns the number of leading elements of a for which expr, with the
+// element bound to name, is truthy. It implements take_while and drop_while.
+func leadingRunLength(a []interface{}, name string, expr expressions.Closure) (int, error) {
+	test := elementPredicate(name, expr)
+	for i, item := range a {
+		ok, err := test(item)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return i, nil
+		}
+	}
+	return len(a), nil
+}
+
+// groupByFilter groups the elements of a by the value of property, in order of
+// each group's first element. Each group is a map with keys "name", "items", and "size".
+func groupByFilter(a []interface{}, property string) []interface{} {
+	var (
+		key     = values.ValueOf(property)
+		result  = []interface{}{}
+		indices = map[string]int{}
+	)
+	for _, item := range a {
+		name := ""
+		if v := values.ValueOf(item).PropertyValue(key).Interface(); v != nil {
+			name = fmt.Sprint(v)
+		}
+		i, ok := indices[name]
+		if !ok {
+			i = len(result)
+			indices[name] = i
+			result = append(result, map[string]interface{}{"name": name, "items": []interface{}{}, "size": 0})
+		}
+		group := result[i].(map[string]interface{})
+		group["items"] = append(group["items"].([]interface{}), item)
+		group["size"] = group["size"].(int) + 1
+	}
+	return result
+}
+
+// whereFilter implements the where filter, which selects the elements of an array whose
+// property equals a value, or is truthy if the value is omitted; and, if reject is true,
+// the reject filter, which selects the other elements. An input that isn't an array is
+// returned unchanged.
+func whereFilter(reject bool) func(interface{}, string, ...interface{}) (interface{}, error) {
+	return func(input interface{}, property string, value ...interface{}) (interface{}, error) {
+		if len(value) > 1 {
+			return nil, fmt.Errorf("wrong number of arguments (given %d, expected 1 or 2)", len(value)+1)
+		}
+		rv := reflect.ValueOf(input)
+		if rv.Kind() != reflect.Array && rv.Kind() != reflect.Slice {
+			return input, nil
+		}
+		key := values.ValueOf(property)
+		result := []interface{}{}
+		for i := 0; i < rv.Len(); i++ {
+			item := rv.Index(i).Interface()
+			pv := values.ValueOf(item).PropertyValue(key)
+			var match bool
+			if len(value) == 0 {
+				match = pv.Test()
+			} else {
+				match = pv.Equal(values.ValueOf(value[0]))
+			}
+			if match != reject {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	}
+}
+
+// inGroupsOfFilter splits a into arrays of n elements, followed by an array of the
+// remaining elements if there are any. If fill is supplied, the last array is padded
+// to n elements with it.
+func inGroupsOfFilter(a []interface{}, n int, fill ...interface{}) ([]interface{}, error) {
+	switch {
+	case len(fill) > 1:
+		return nil, fmt.Errorf("wrong number of arguments (given %d, expected 1 or 2)", len(fill)+1)
+	case n <= 0:
+		return nil, fmt.Errorf("in_groups_of requires a positive group size; got %d", n)
+	}
+	result := make([]interface{}, 0, (len(a)+n-1)/n)
+	for i := 0; i < len(a); i += n {
+		end := i + n
+		if end > len(a) {
+			end = len(a)
+		}
+		group := make([]interface{}, end-i, n)
+		copy(group, a[i:end])
+		if len(fill) > 0 {
+			for len(group) < n {
+				group = append(group, fill[0])
+			}
+		}
+		result = append(result, group)
+	}
+	return result, nil
+}
+
+// joinFilter joins the elements of a other than nil with sep, except that it uses last,
+// if this is supplied, between the last two elements.
+func joinFilter(a []interface{}, sep func(string) string, last func(string) string) interface{} {
 	ss := make([]string, 0, len(a))
 	s := sep(" ")
 	for _, v := range a {
@@ -227,9 +442,58 @@ func joinFilter(a []interface{}, sep func(string) string) interface{} {
 			ss = append(ss, fmt.Sprint(v))
 		}
 	}
+	if n := len(ss); n > 1 {
+		return strings.Join(ss[:n-1], s) + last(s) + ss[n-1]
+	}
 	return strings.Join(ss, s)
 }
 
+// encodeEntitiesFilter replaces each non-ASCII character by a numeric character reference.
+func encodeEntitiesFilter(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "&#%d;", r)
+		}
+	}
+	return b.String()
+}
+
+// readingTimeFilter returns the number of minutes, rounded up and at least one, that it
+// takes to read the text of s at wpm words per minute.
+func readingTimeFilter(s string, wpm func(int) int) (int, error) {
+	rate := wpm(200)
+	if rate <= 0 {
+		return 0, fmt.Errorf("reading_time: words per minute must be positive; got %d", rate)
+	}
+	words := len(strings.Fields(stripHTMLRE.ReplaceAllString(s, " ")))
+	minutes := (words + rate - 1) / rate
+	if minutes < 1 {
+		minutes = 1
+	}
+	return minutes, nil
+}
+
+// sliceFilter returns a copy of a[start:end]. It implements the pop and shift filters,
+// which return new arrays instead of sharing the input's.
+func sliceFilter(a []interface{}, start, end int) []interface{} {
+	return append([]interface{}{}, a[start:end]...)
+}
+
+// clampCount returns n, limited to the range from 0 to length.
+func clampCount(n, length int) int {
+	switch {
+	case n < 0:
+		return 0
+	case n > length:
+		return length
+	default:
+		return n
+	}
+}
+
 func reverseFilter(a []interface{}) interface{} {
 	result := make([]interface{}, len(a))
 	for i, x := range a {
@@ -238,6 +502,160 @@ func reverseFilter(a []interface{}) interface{} {
 	return result
 }
 
+var translationPlaceholderRE = regexp.MustCompile(`%\{(\w+)\}`)
+
+// translateFilter implements the t filter. It looks up key in the translations for the
+// "locale" setting, then for the language of that locale (for example "fr" for "fr-CA"),
+// and then for the "default_locale" setting, and replaces the placeholders in the
+// translation by the keyword arguments. A key without a translation is returned unchanged.
+func translateFilter(fc expressions.FilterContext, key string, kwargs expressions.KeywordArgs) string {
+	cfg := fc.Config()
+	defaultLocale, ok := cfg.Settings["default_locale"].(string)
+	if !ok {
+		defaultLocale = "en"
+	}
+	var locales []string
+	if locale, ok := cfg.Settings["locale"].(string); ok {
+		locales = append(locales, locale)
+		if i := strings.IndexAny(locale, "-_"); i > 0 {
+			locales = append(locales, locale[:i])
+		}
+	}
+	locales = append(locales, defaultLocale)
+	for _, locale := range locales {
+		if s, ok := cfg.Translations[locale][key]; ok {
+			return translationPlaceholderRE.ReplaceAllStringFunc(s, func(m string) string {
+				if v, ok := kwargs[m[2:len(m)-1]]; ok {
+					return fmt.Sprint(v)
+				}
+				return m
+			})
+		}
+	}
+	return key
+}
+
+// truncateFilter truncates s to length characters, including ellipsis, if it is longer
+// than length.
+func truncateFilter(s string, length func(int) int, ellipsis func(string) string) string {
+	n := length(50)
+	el := ellipsis("...")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	keep := n - utf8.RuneCountInString(el)
+	// runes aren't bytes; find the byte offset of the rune at keep
+	end := 0
+	for i := 0; i < keep && end < len(s); i++ {
+		_, size := utf8.DecodeRuneInString(s[end:])
+		end += size
+	}
+	return s[:end] + el
+}
+
+// truncateWordsFilter truncates s to length words, followed by ellipsis. With the
+// keyword argument html: true, HTML tags don't count as words and aren't broken, and the
+// result closes the elements that are open where it is truncated.
+func truncateWordsFilter(s string, length func(int) int, ellipsis func(string) string, kwargs expressions.KeywordArgs) string {
+	el := ellipsis("...")
+	n := length(15)
+	if html, ok := kwargs["html"]; ok && values.ValueOf(html).Test() {
+		return truncateHTMLWords(s, n, el)
+	}
+	// end is the end of the nth word, or of s if it has fewer words
+	end := 0
+	for i := 0; i < n; i++ {
+		start := strings.IndexFunc(s[end:], func(r rune) bool { return !unicode.IsSpace(r) })
+		if start < 0 {
+			return s
+		}
+		end += start
+		if j := strings.IndexFunc(s[end:], unicode.IsSpace); j >= 0 {
+			end += j
+		} else {
+			end = len(s)
+		}
+	}
+	if end == 0 || strings.TrimFunc(s[end:], unicode.IsSpace) == "" {
+		return s
+	}
+	return s[:end] + el
+}
+
+var (
+	htmlTagRE  = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)?[^>]*?(/?)>|<!--[\s\S]*?-->|<![^>]*>`)
+	htmlWordRE = regexp.MustCompile(`\S+`)
+)
+
+// htmlVoidElements are the elements that don't have end tags.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// truncateHTMLWords implements truncatewords with html: true.
+func truncateHTMLWords(s string, n int, ellipsis string) string {
+	var (
+		open  []string // the names of the open elements
+		count = 0
+		text  = 0 // the start of the current run of text
+	)
+	// cut returns the result of truncating s at i, or false if s has no more words after i.
+	cut := func(i int) (string, bool) {
+		rest := htmlTagRE.ReplaceAllString(s[i:], "")
+		if strings.TrimFunc(rest, unicode.IsSpace) == "" {
+			return "", false
+		}
+		var b strings.Builder
+		b.WriteString(s[:i])
+		b.WriteString(ellipsis)
+		for j := len(open) - 1; j >= 0; j-- {
+			b.WriteString("</" + open[j] + ">")
+		}
+		return b.String(), true
+	}
+	// words counts the words of s[start:end], and truncates s if this reaches n.
+	words := func(start, end int) (string, bool) {
+		for _, m := range htmlWordRE.FindAllStringIndex(s[start:end], -1) {
+			count++
+			if count == n {
+				return cut(start + m[1])
+			}
+		}
+		return "", false
+	}
+	if n <= 0 {
+		return s
+	}
+	for _, m := range htmlTagRE.FindAllStringSubmatchIndex(s, -1) {
+		if out, ok := words(text, m[0]); ok {
+			return out
+		} else if count >= n {
+			return s
+		}
+		text = m[1]
+		if m[4] < 0 {
+			continue // a comment or declaration
+		}
+		name := strings.ToLower(s[m[4]:m[5]])
+		switch {
+		case m[3] > m[2]: // an end tag
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == name {
+					open = open[:j]
+					break
+				}
+			}
+		case m[7] == m[6] && !htmlVoidElements[name]:
+			open = append(open, name)
+		}
+	}
+	if out, ok := words(text, len(s)); ok {
+		return out
+	}
+	return s
+}
+
 var wsre = regexp.MustCompile(`[[:space:]]+`)
 
 func splitFilter(s, sep string) interface{} {
@@ -253,7 +671,436 @@ func splitFilter(s, sep string) interface{} {
 	return result
 }
 
-func uniqFilter(a []interface{}) (result []interface{}) {
+// mergeFilter returns a new map with the entries of a and b. The entries of b take
+// precedence. If deep is true, maps that are values of the same key in a and b are
+// merged recursively.
+func mergeFilter(a, b interface{}, deep func(bool) bool) (interface{}, error) {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if av.Kind() != reflect.Map || bv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("merge requires two maps; got %T and %T", a, b)
+	}
+	return mergeMaps(av, bv, deep(false)), nil
+}
+
+func mergeMaps(a, b reflect.Value, deep bool) map[string]interface{} {
+	result := make(map[string]interface{}, a.Len()+b.Len())
+	for _, m := range []reflect.Value{a, b} {
+		iter := m.MapRange()
+		for iter.Next() {
+			k, v := fmt.Sprint(iter.Key().Interface()), iter.Value().Interface()
+			if deep {
+				pv, vv := reflect.ValueOf(result[k]), reflect.ValueOf(v)
+				if pv.Kind() == reflect.Map && vv.Kind() == reflect.Map {
+					v = mergeMaps(pv, vv, true)
+				}
+			}
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// pathSegmentsFilter returns a breadcrumb trail for a path: a {name, url} map for each
+// non-empty segment, where url is the cumulative path up to and including the segment.
+func pathSegmentsFilter(s string, separator func(string) string) []interface{} {
+	sep := separator("/")
+	result := []interface{}{}
+	url := ""
+	for _, name := range strings.Split(s, sep) {
+		if name == "" {
+			continue
+		}
+		url += sep + name
+		result = append(result, map[string]interface{}{"name": name, "url": url})
+	}
+	return result
+}
+
+// percentOfFilter returns part as a percentage of whole, rounded to places decimal places,
+// with halves rounded away from zero. Like roundFilter, it returns an int if places is zero
+// or negative, and a float64 otherwise. It returns 0 if whole is zero, so that an empty
+// total renders as 0% rather than an error.
+func percentOfFilter(part, whole float64, places func(int) int) interface{} {
+	pl := places(0)
+	var r float64
+	if whole != 0 {
+		exp := math.Pow10(pl)
+		r = math.Round(part/whole*100*exp) / exp
+	}
+	if pl <= 0 {
+		return int(r)
+	}
+	return r
+}
+
+// camelCaseFilter returns a filter that splits its input at spaces, underscores, and
+// hyphens, and joins the segments with the first letter of each in upper case. If
+// upper is false, the first letter of the result is in lower case instead.
+func camelCaseFilter(upper bool) func(string) string {
+	return func(s string) string {
+		segments := strings.FieldsFunc(s, func(r rune) bool {
+			return r == '_' || r == '-' || unicode.IsSpace(r)
+		})
+		var b strings.Builder
+		for i, seg := range segments {
+			r, n := utf8.DecodeRuneInString(seg)
+			if i > 0 || upper {
+				r = unicode.ToUpper(r)
+			} else {
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+			b.WriteString(seg[n:])
+		}
+		return b.String()
+	}
+}
+
+// escapeOnceRE matches an entity reference, or a character that escape would escape.
+var escapeOnceRE = regexp.MustCompile(`&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);|[&<>"']`)
+
+// escapeOnceFilter escapes s as escape does, except that it leaves entity references as is.
+func escapeOnceFilter(s string) string {
+	return escapeOnceRE.ReplaceAllStringFunc(s, func(m string) string {
+		if len(m) > 1 {
+			return m
+		}
+		return html.EscapeString(m)
+	})
+}
+
+// highlightFilter escapes s as escape does, and wraps each case-insensitive occurrence
+// of query in an element with the class "highlight". The element is tag, which
+// defaults to "strong". An empty query returns s as is.
+func highlightFilter(s, query string, tag func(string) string) string {
+	if query == "" {
+		return s
+	}
+	var (
+		name          = html.EscapeString(tag("strong"))
+		lower, offset = lowerWithOffsets(s)
+		q             = strings.ToLower(query)
+		b             strings.Builder
+		start         = 0
+	)
+	for i := 0; ; {
+		j := strings.Index(lower[i:], q)
+		if j < 0 {
+			break
+		}
+		m0, m1 := offset[i+j], offset[i+j+len(q)]
+		b.WriteString(html.EscapeString(s[start:m0]))
+		fmt.Fprintf(&b, `<%s class="highlight">%s</%s>`, name, html.EscapeString(s[m0:m1]), name)
+		start, i = m1, i+j+len(q)
+	}
+	b.WriteString(html.EscapeString(s[start:]))
+	return b.String()
+}
+
+// lowerWithOffsets returns s lowercased as strings.ToLower does, and, for each byte offset
+// in the result that begins a rune, the offset of the corresponding rune in s. A rune's
+// lowercase form can have a different length, so the offsets can differ.
+func lowerWithOffsets(s string) (string, []int) {
+	var (
+		b      strings.Builder
+		offset = make([]int, 0, len(s)+1)
+	)
+	b.Grow(len(s))
+	for i, r := range s {
+		n, _ := b.WriteRune(unicode.ToLower(r))
+		for k := 0; k < n; k++ {
+			offset = append(offset, i)
+		}
+	}
+	return b.String(), append(offset, len(s))
+}
+
+// normalizeURLFilter adds the https scheme to s if it doesn't have one, and lowercases
+// its scheme and host.
+func normalizeURLFilter(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") {
+		s = "https://" + strings.TrimPrefix(s, "//")
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
+
+// toCSVFilter formats a as a CSV record, or as one record per line if its elements are
+// arrays. Fields are quoted as RFC 4180 requires. delimiter is a single character; it
+// defaults to a comma.
+func toCSVFilter(a []interface{}, delimiter func(string) string) (string, error) {
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	delim := []rune(delimiter(","))
+	if len(delim) != 1 {
+		return "", fmt.Errorf("to_csv delimiter must be a single character")
+	}
+	w.Comma = delim[0]
+	rows := [][]interface{}{a}
+	if len(a) > 0 && a[0] != nil && (reflect.TypeOf(a[0]).Kind() == reflect.Array || reflect.TypeOf(a[0]).Kind() == reflect.Slice) {
+		rows = make([][]interface{}, len(a))
+		for i, row := range a {
+			r, err := values.Convert(row, reflect.TypeOf(rows[i]))
+			if err != nil {
+				return "", err
+			}
+			rows[i] = r.([]interface{})
+		}
+	}
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, field := range row {
+			if field != nil {
+				record[i] = values.MustConvert(field, reflect.TypeOf("")).(string)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// arithmeticFilter returns a filter that applies intOp if both of its operands are
+// integers, and floatOp otherwise. The operands are coerced by coerceNumber.
+func arithmeticFilter(intOp func(a, b int) int, floatOp func(a, b float64) float64) func(a, b interface{}) (interface{}, error) {
+	return func(a, b interface{}) (interface{}, error) {
+		x, err := coerceNumber(a)
+		if err != nil {
+			return nil, err
+		}
+		y, err := coerceNumber(b)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := x.(int); ok {
+			if j, ok := y.(int); ok {
+				return intOp(i, j), nil
+			}
+		}
+		return floatOp(toFloat(x), toFloat(y)), nil
+	}
+}
+
+// boundFilter returns a filter that returns the larger of its input and argument, or
+// the smaller if upper is true. The result is an int if the selected operand is an
+// integer, and a float64 otherwise. The operands are coerced by coerceNumber.
+func boundFilter(upper bool) func(a, b interface{}) (interface{}, error) {
+	return func(a, b interface{}) (interface{}, error) {
+		x, err := coerceNumber(a)
+		if err != nil {
+			return nil, err
+		}
+		y, err := coerceNumber(b)
+		if err != nil {
+			return nil, err
+		}
+		if (toFloat(y) < toFloat(x)) == upper {
+			return y, nil
+		}
+		return x, nil
+	}
+}
+
+// dividedByFilter divides a by b. It uses integer division, rounding down, if both are
+// integers, and float division otherwise. The operands are coerced by coerceNumber.
+func dividedByFilter(a, b interface{}) (interface{}, error) {
+	y, err := coerceNumber(b)
+	if err != nil {
+		return nil, err
+	}
+	if toFloat(y) == 0 {
+		return nil, fmt.Errorf("divided by 0")
+	}
+	x, err := coerceNumber(a)
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := x.(int); ok {
+		if j, ok := y.(int); ok {
+			q := i / j
+			if (i%j != 0) && ((i < 0) != (j < 0)) {
+				q--
+			}
+			return q, nil
+		}
+	}
+	return toFloat(x) / toFloat(y), nil
+}
+
+// moduloFilter returns the remainder of dividing a by b. Like dividedByFilter, it rounds
+// the quotient down, so that the remainder has the sign of b. The result is an int if
+// both operands are integers, and a float64 otherwise. The operands are coerced by
+// coerceNumber.
+//
+// As in Ruby Liquid, which converts float operands to BigDecimal, the remainder of float
+// operands is that of their decimal representations: 183.357 | modulo: 12 is 3.357.
+func moduloFilter(a, b interface{}) (interface{}, error) {
+	x, err := coerceNumber(a)
+	if err != nil {
+		return nil, err
+	}
+	y, err := coerceNumber(b)
+	if err != nil {
+		return nil, err
+	}
+	if toFloat(y) == 0 {
+		return nil, fmt.Errorf("divided by 0")
+	}
+	if i, ok := x.(int); ok {
+		if j, ok := y.(int); ok {
+			r := i % j
+			if r != 0 && (r < 0) != (j < 0) {
+				r += j
+			}
+			return r, nil
+		}
+	}
+	return decimalModulo(toFloat(x), toFloat(y)), nil
+}
+
+// decimalModulo returns the floored remainder of f and g, computed on their shortest
+// decimal representations. It falls back to math.Mod if these have too many digits to
+// be scaled exactly to integers.
+func decimalModulo(f, g float64) float64 {
+	places := decimalPlaces(f)
+	if p := decimalPlaces(g); p > places {
+		places = p
+	}
+	scale := math.Pow10(places)
+	if places <= 15 && math.Abs(f*scale) < 1<<53 && math.Abs(g*scale) < 1<<53 {
+		i, j := int64(math.Round(f*scale)), int64(math.Round(g*scale))
+		r := i % j
+		if r != 0 && (r < 0) != (j < 0) {
+			r += j
+		}
+		return float64(r) / scale
+	}
+	r := math.Mod(f, g)
+	if r != 0 && (r < 0) != (g < 0) {
+		r += g
+	}
+	return r
+}
+
+// decimalPlaces returns the number of digits after the decimal point in the shortest
+// decimal representation of f.
+func decimalPlaces(f float64) int {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// absFilter returns the absolute value of a, as an int if a is an integer and a float64
+// otherwise. The operand is coerced by coerceNumber.
+func absFilter(a interface{}) (interface{}, error) {
+	n, err := coerceNumber(a)
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := n.(int); ok {
+		if i < 0 {
+			i = -i
+		}
+		return i, nil
+	}
+	return math.Abs(toFloat(n)), nil
+}
+
+// roundFilter rounds n to places decimal places, rounding halves away from zero. It
+// returns an int if places is zero or negative, and a float64 otherwise.
+func roundFilter(n float64, places func(int) int) interface{} {
+	pl := places(0)
+	exp := math.Pow10(pl)
+	r := math.Round(n*exp) / exp
+	if pl <= 0 {
+		return int(r)
+	}
+	return r
+}
+
+// toNumber converts value to an int if it is an integer or a string that represents
+// one, and otherwise to a float64.
+func toNumber(value interface{}) (interface{}, error) {
+	value = values.ToLiquid(value)
+	if value == nil {
+		return 0, nil
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return values.Convert(value, reflect.TypeOf(0))
+	case reflect.String:
+		if n, err := strconv.Atoi(strings.TrimSpace(reflect.ValueOf(value).String())); err == nil {
+			return n, nil
+		}
+	}
+	return values.Convert(value, reflect.TypeOf(0.0))
+}
+
+// coerceNumber converts an operand of an arithmetic filter to a number, as toNumber
+// does, except that a string that doesn't represent a number is 0, as in Shopify.
+// nil is also 0.
+func coerceNumber(value interface{}) (interface{}, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		if reflect.ValueOf(values.ToLiquid(value)).Kind() == reflect.String {
+			return 0, nil
+		}
+	}
+	return n, err
+}
+
+// toFloat converts a result of toNumber to a float64.
+func toFloat(n interface{}) float64 {
+	if i, ok := n.(int); ok {
+		return float64(i)
+	}
+	return n.(float64)
+}
+
+// uniqFilter removes the duplicate elements of a, keeping the first occurrence of each.
+// If a property is supplied, two elements are duplicates if their values of the property
+// are equal; elements that don't have the property are duplicates of each other.
+func uniqFilter(a []interface{}, property ...string) ([]interface{}, error) {
+	switch len(property) {
+	case 0:
+		return uniqElements(a), nil
+	case 1:
+		key := values.ValueOf(property[0])
+		result := []interface{}{}
+		var seen []values.Value
+	outer:
+		for _, item := range a {
+			pv := values.ValueOf(item).PropertyValue(key)
+			for _, other := range seen {
+				if pv.Equal(other) {
+					continue outer
+				}
+			}
+			seen = append(seen, pv)
+			result = append(result, item)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("wrong number of arguments (given %d, expected 0 or 1)", len(property))
+	}
+}
+
+// uniqElements removes the elements of a that are equal to an earlier element.
+func uniqElements(a []interface{}) (result []interface{}) {
 	seenMap := map[interface{}]bool{}
 	seen := func(item interface{}) bool {
 		if k := reflect.TypeOf(item).Kind(); k < reflect.Array || k == reflect.Ptr || k == reflect.UnsafePointer {
@@ -3,6 +3,7 @@ package filters
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,6 +29,16 @@ var filterTests = []struct {
 	{`"true" | default: 2.99`, "true"},
 	{`4.99 | default: 2.99`, 4.99},
 	{`fruits | default: 2.99 | join`, "apples oranges peaches plums"},
+	{`false | default: 2.99, allow_false: true`, false},
+	{`false | default: 2.99, allow_false: false`, 2.99},
+	{`nil | default: 2.99, allow_false: true`, 2.99},
+	{`"" | default: 2.99, allow_false: true`, 2.99},
+	{`empty_array | default: 2.99, allow_false: true`, 2.99},
+	{`empty_map | default: 2.99, allow_false: true`, 2.99},
+	{`true | default: 2.99, allow_false: true`, true},
+	{`" " | default: 2.99`, " "},
+	{`0 | default: 2.99`, 0},
+	{`map | default: 2.99 | inspect`, `map[string]interface {}{"a": int(1)}`},
 	{`"string" | json`, "\"string\""},
 	{`true | json`, "true"},
 	{`1 | json`, "1"},
@@ -40,19 +51,86 @@ var filterTests = []struct {
 	{`",John, Paul, George, Ringo" | split: ", " | join: " and "`, ",John and Paul and George and Ringo"},
 	{`"John, Paul, George, Ringo," | split: ", " | join: " and "`, "John and Paul and George and Ringo,"},
 	{`animals | sort | join: ", "`, "Sally Snake, giraffe, octopus, zebra"},
-	{`sort_prop | sort: "weight" | inspect`, `[{"weight":null},{"weight":1},{"weight":3},{"weight":5}]`},
+	{`"a,b,c" | split: "," | join: ", ", " and "`, "a, b and c"},
+	{`"1,2,3,4,5,6" | split: "," | in_groups_of: 3`, []interface{}{[]interface{}{"1", "2", "3"}, []interface{}{"4", "5", "6"}}},
+	{`"1,2,3,4,5" | split: "," | in_groups_of: 2`, []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}, []interface{}{"5"}}},
+	{`"1,2,3,4" | split: "," | in_groups_of: 3, "-"`, []interface{}{[]interface{}{"1", "2", "3"}, []interface{}{"4", "-", "-"}}},
+	{`"1,2,3" | split: "," | in_groups_of: 2, nil`, []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", nil}}},
+	{`"1,2,3" | split: "," | in_groups_of: 5`, []interface{}{[]interface{}{"1", "2", "3"}}},
+	{`fruits | in_groups_of: 3 | map: "size" | join`, "3 1"},
+	{`fruits | in_groups_of: 3 | last | first`, "plums"},
+	{`empty_array | in_groups_of: 3`, []interface{}{}},
+	{`fruits | join: ", ", ", and "`, "apples, oranges, peaches, and plums"},
+	{`"a,b" | split: "," | join: ", ", " and "`, "a and b"},
+	{`"a" | split: "," | join: ", ", " and "`, "a"},
+	{`empty_array | join: ", ", " and "`, ""},
+	{`"a,b,c" | split: "," | join: "-"`, "a-b-c"},
+	{`"a,b,c" | split: "," | join`, "a b c"},
+	{`sort_prop | sort: "weight" | inspect`, `[]interface {}{map[string]interface {}{"weight": nil}, map[string]interface {}{"weight": int(1)}, map[string]interface {}{"weight": int(3)}, map[string]interface {}{"weight": int(5)}}`},
+	{`sort_ties | sort: "weight" | map: "name" | join`, "g c e a d b f"},
+	{`sort_ties | sort_natural: "group" | map: "name" | join`, "f a b d e g c"},
+	{`sort_tie_strings | sort_natural | join`, "a A a b B"},
 	{`fruits | reverse | join: ", "`, "plums, peaches, oranges, apples"},
 	{`fruits | first`, "apples"},
 	{`fruits | last`, "plums"},
 	{`empty_array | first`, nil},
 	{`empty_array | last`, nil},
 	{`empty_array | last`, nil},
+	{`fruits | first: 2`, []interface{}{"apples", "oranges"}},
+	{`fruits | last: 3`, []interface{}{"oranges", "peaches", "plums"}},
+	{`fruits | first: 1`, []interface{}{"apples"}},
+	{`fruits | last: 0`, []interface{}{}},
+	{`fruits | first: 10`, []interface{}{"apples", "oranges", "peaches", "plums"}},
+	{`fruits | last: 10`, []interface{}{"apples", "oranges", "peaches", "plums"}},
+	{`empty_array | first: 2`, []interface{}{}},
+	{`"héllo" | first: 2`, "hé"},
+	{`"hello" | last: 3`, "llo"},
+	{`"hello" | last: 10`, "hello"},
+	{`fruits.first == (fruits | first)`, true},
+	{`fruits.last == (fruits | last)`, true},
+	{`fruits.size == (fruits | size)`, true},
+	{`empty_array.first`, nil},
+	{`empty_array.last`, nil},
+	{`empty_array.size`, 0},
+	{`"hello" | first`, "h"},
+	{`"hello" | last`, "o"},
+	{`"héllo".first == ("héllo" | first)`, true},
+	{`"hello".last == ("hello" | last)`, true},
+	{`"hello".size == ("hello" | size)`, true},
+	{`"" | first`, nil},
+	{`"".last`, nil},
+	{`nil | first`, nil},
+	{`map | first`, nil},
+	{`map.first`, nil},
+	{`map.size`, 1},
 	{`dup_ints | uniq | join`, "1 2 3"},
 	{`dup_strings | uniq | join`, "one two three"},
 	{`dup_maps | uniq | map: "name" | join`, "m1 m2 m3"},
+	{`dup_ids | uniq: "id" | map: "name" | join`, "a b d"},
+	{`dup_ids | uniq: "name" | size`, 5},
+	{`dup_ids | uniq: "missing" | map: "name" | join`, "a"},
+	{`empty_array | uniq: "id"`, []interface{}{}},
 	{`mixed_case_array | sort_natural | join`, "a B c"},
 	{`mixed_case_hash_values | sort_natural: 'key' | map: 'key' | join`, "a B c"},
 
+	{`fruits | push: "kiwis" | join: ", "`, "apples, oranges, peaches, plums, kiwis"},
+	{`fruits | push: "kiwis" | size`, 5},
+	{`fruits | pop | join: ", "`, "apples, oranges, peaches"},
+	{`fruits | pop: 2 | join: ", "`, "apples, oranges"},
+	{`fruits | pop: 10 | size`, 0},
+	{`fruits | shift | join: ", "`, "oranges, peaches, plums"},
+	{`fruits | shift: 3 | join: ", "`, "plums"},
+	{`fruits | unshift: "kiwis" | join: ", "`, "kiwis, apples, oranges, peaches, plums"},
+	{`fruits | shift | pop | push: "a" | unshift: "z" | join`, "z oranges peaches a"},
+	{`fruits | push: "kiwis" | pop | join: ", "`, "apples, oranges, peaches, plums"},
+	{`empty_array | push: 1 | join`, "1"},
+	{`empty_array | unshift: 1 | join`, "1"},
+	{`empty_array | pop`, []interface{}{}},
+	{`empty_array | shift`, []interface{}{}},
+	{`nil | pop`, []interface{}{}},
+	{`nil | shift`, []interface{}{}},
+	{`nil | push: 1`, []interface{}{1}},
+
 	{`map_slice_has_nil | compact | join`, `a b`},
 	{`map_slice_2 | first`, `b`},
 	{`map_slice_2 | last`, `a`},
@@ -65,10 +143,69 @@ var filterTests = []struct {
 
 	{`struct_slice | map: "str" | join`, `a b c`},
 
+	{`products | take_while: "p", "p.in_stock" | map: "title" | join`, "a b"},
+	{`products | drop_while: "p", "p.in_stock" | map: "title" | join`, "c d"},
+	{`products | take_while: "p", "p.price < 100" | size`, 4},
+	{`products | drop_while: "p", "p.price < 100" | size`, 0},
+	{`products | take_while: "p", "p.price > 100" | size`, 0},
+	{`products | drop_while: "p", "p.price > 100" | map: "title" | join`, "a b c d"},
+	{`products | take_while: "p", "p.price < products[2].price" | map: "title" | join`, "a b"},
+	{`products | drop_while: "p", "(p.title | upcase) != 'C'" | map: "title" | join`, "c d"},
+	{`products | take_while: "fruits", "fruits.in_stock" | size`, 2},
+	{`fruits | first`, "apples"},
+	{`empty_array | take_while: "p", "p" | size`, 0},
+	{`docs | where: "draft" | map: "title" | join`, "a"},
+	{`docs | reject: "draft" | map: "title" | join`, "b c d"},
+	{`docs | where: "draft", true | map: "title" | join`, "a"},
+	{`docs | reject: "draft", true | map: "title" | join`, "b c d"},
+	{`docs | where: "draft", false | map: "title" | join`, "b"},
+	{`docs | reject: "draft", false | map: "title" | join`, "a c d"},
+	{`docs | where: "hidden" | map: "title" | join`, "c"},
+	{`docs | reject: "hidden" | map: "title" | join`, "a b d"},
+	{`docs | where: "title", "b" | map: "title" | join`, "b"},
+	{`docs | reject: "title", "b" | map: "title" | join`, "a c d"},
+	{`flags | where: "a" | map: "title" | join`, "true 0 empty string"},
+	{`flags | where: "a", true | map: "title" | join`, "true"},
+	{`flags | where: "a", false | map: "title" | join`, "false"},
+	{`flags | where: "a", 0 | map: "title" | join`, "0"},
+	{`flags | where: "a", "" | map: "title" | join`, "empty"},
+	{`flags | reject: "a" | map: "title" | join`, "false nil missing"},
+	{`flags | reject: "a", false | map: "title" | join`, "true 0 empty string nil missing"},
+	{`docs | where: "missing" | size`, 0},
+	{`docs | reject: "missing" | size`, 4},
+	{`empty_array | where: "draft" | size`, 0},
+	{`empty_array | reject: "draft" | size`, 0},
+	{`"str" | where: "draft"`, "str"},
+	{`"str" | reject: "draft"`, "str"},
+	{`map | reject: "a"`, map[string]interface{}{"a": 1}},
+	{`nil | reject: "a"`, nil},
+	{`events | chunk_by: "date" | size`, 4},
+	{`events | chunk_by: "date" | map: "size" | join`, "2 1 1 2"},
+	{`(events | chunk_by: "date")[2] | map: "title" | join`, "d"},
+	{`(events | chunk_by: "date")[3] | map: "title" | join`, "e f"},
+	{`pages | chunk_by: "category" | size`, 7},
+	{`empty_array | chunk_by: "date" | size`, 0},
+	{`csv_row | to_csv`, `a,"b,c","say ""hi""","line 1` + "\n" + `line 2",1.5,`},
+	{`csv_rows | to_csv`, "name,qty\napple,3\n\"banana, ripe\",12"},
+	{`csv_rows | to_csv: ";"`, "name;qty\napple;3\nbanana, ripe;12"},
+	{`empty_array | to_csv`, ""},
+	{`pages | group_by: "category" | map: "name" | join: ","`, "business,celebrities,,lifestyle,sports,technology"},
+	{`pages | group_by: "category" | map: "size" | join`, "1 1 2 1 1 1"},
+	{`(pages | group_by: "category")[2].items | map: "name" | join: ","`, "page 3,page 6"},
+	{`(pages | group_by: "category")[0].items | map: "name" | join: ","`, "page 1"},
+	{`struct_slice | group_by: "str" | map: "name" | join`, "a b c"},
+	{`empty_array | group_by: "category" | size`, 0},
+
 	// date filters
 	{`article.published_at | date`, "Fri, Jul 17, 15"},
 	{`article.published_at | date: "%a, %b %d, %y"`, "Fri, Jul 17, 15"},
 	{`article.published_at | date: "%Y"`, "2015"},
+	{`article.published_at | date: "2006"`, "2015"},
+	{`article.published_at | date: "Mon, Jan 02, 06"`, "Fri, Jul 17, 15"},
+	{`"2017-02-08 19:00:00" | date: "2006-01-02 15:04"`, "2017-02-08 19:00"},
+	{`"2017-02-08 19:00:00" | date: "15h"`, "19h"},
+	{`"2017-02-08 19:00:00" | date: "%Y 2006 Jan 15"`, "2017 2006 Jan 15"},
+	{`"2017-02-08 19:00:00" | date: "noon"`, "noon"},
 	{`"2017-02-08 19:00:00 -05:00" | date`, "Wed, Feb 08, 17"},
 	{`"2017-05-04 08:00:00 -04:00" | date: "%b %d, %Y"`, "May 04, 2017"},
 	{`"2017-02-08 09:00:00" | date: "%H:%M"`, "09:00"},
@@ -80,12 +217,67 @@ var filterTests = []struct {
 	{`"2017-07-09" | date: "%d/%m"`, "09/07"},
 	{`"2017-07-09" | date: "%e/%m"`, " 9/07"},
 	{`"2017-07-09" | date: "%-d/%-m"`, "9/7"},
+	{`"09/07/2017" | parse_date: "%d/%m/%Y" | date: "%Y-%m-%d"`, "2017-07-09"},
+	{`"2017-07-09T10:40" | parse_date: "%Y-%m-%dT%H:%M", "UTC" | date: "%H:%M %Z"`, "10:40 UTC"},
+	{`"Jul 9, 17 3:04 PM" | parse_date: "%b %-d, %y %-I:%M %p" | date: "%Y-%m-%d %H:%M"`, "2017-07-09 15:04"},
+	{`"2017-07-09" | parse_date: "%Y-%m-%d" | date: "%j"`, "190"},
 
 	// sequence (array or string) filters
 	{`"Ground control to Major Tom." | size`, 28},
+	{`"The cat sat on the Cat mat" | highlight: "cat"`, `The <strong class="highlight">cat</strong> sat on the <strong class="highlight">Cat</strong> mat`},
+	{`"Ünïcode ünïcode" | highlight: "ÜNÏ"`, `<strong class="highlight">Ünï</strong>code <strong class="highlight">ünï</strong>code`},
+	{`"<b>Tom & Jerry</b>" | highlight: "tom & j"`, `&lt;b&gt;<strong class="highlight">Tom &amp; J</strong>erry&lt;/b&gt;`},
+	{`"x+y (a|b) [A|B]" | highlight: "(A|b)"`, `x+y <strong class="highlight">(a|b)</strong> [A|B]`},
+	{`"a.*b A.*B ab" | highlight: ".*B"`, `a<strong class="highlight">.*b</strong> A<strong class="highlight">.*B</strong> ab`},
+	{`"Kelvin KELVIN" | highlight: "kel"`, `<strong class="highlight">Kel</strong>vin <strong class="highlight">KEL</strong>VIN`},
+	{`"a.b.c" | highlight: "."`, `a<strong class="highlight">.</strong>b<strong class="highlight">.</strong>c`},
+	{`"search results" | highlight: "result", "mark"`, `search <mark class="highlight">result</mark>s`},
+	{`"no match" | highlight: "xyz"`, "no match"},
+	{`"<b>unchanged</b>" | highlight: ""`, "<b>unchanged</b>"},
+	{`123 | highlight: 2`, `1<strong class="highlight">2</strong>3`},
+	{`"héllo, 世界" | size`, 9},
+	{`"héllo, 世界".size`, 9},
+	{`"a,b;c" | split: ";" | map: "size" | join`, "3 1"},
+	{`map | size`, 1},
+	{`map_slice_2 | size`, 2},
+	{`nil | size`, 0},
+	{`undefined_variable | size`, 0},
 	{`"apples, oranges, peaches, plums" | split: ", " | size`, 4},
 
+	// map filters
+	{`(defaults | merge: overrides).title`, "Untitled"},
+	{`(defaults | merge: overrides).color`, "red"},
+	{`(overrides | merge: defaults).color`, "blue"},
+	{`(defaults | merge: overrides).layout.width`, 100},
+	{`(defaults | merge: overrides).layout.sidebar`, nil},
+	{`(defaults | merge: overrides, true).layout.width`, 100},
+	{`(defaults | merge: overrides, true).layout.sidebar`, true},
+	{`(defaults | merge: overrides, true).color`, "red"},
+	{`(defaults | merge: overrides).size`, 3},
+	{`(defaults | merge: empty_map).size`, 3},
+	{`defaults.color`, "blue"},
+
 	// string filters
+	{`"hello world_foo-bar" | camelcase`, "HelloWorldFooBar"},
+	{`"user@example.com" | is_email`, true},
+	{`"first.last+tag@sub.example.co.uk" | is_email`, true},
+	{`"user@" | is_email`, false},
+	{`"not an email" | is_email`, false},
+	{`"User <user@example.com>" | is_email`, false},
+	{`"https://example.com/path?q=1" | is_url`, true},
+	{`"ftp://files.example.com" | is_url`, true},
+	{`"example.com" | is_url`, false},
+	{`"http://[::1" | is_url`, false},
+	{`"Example.COM/Path" | normalize_url`, "https://example.com/Path"},
+	{`"//Example.com" | normalize_url`, "https://example.com"},
+	{`"HTTP://WWW.Example.com:8080/a?b=C" | normalize_url`, "http://www.example.com:8080/a?b=C"},
+	{`"  hello__world  " | camelize`, "HelloWorld"},
+	{`"helloWorld" | camelcase`, "HelloWorld"},
+	{`"HelloWorld" | lower_camelcase`, "helloWorld"},
+	{`"hello world_foo-bar" | lower_camelcase`, "helloWorldFooBar"},
+	{`"élan vital" | camelcase`, "ÉlanVital"},
+	{`123 | camelcase`, "123"},
+	{`"" | camelcase`, ""},
 	{`"Take my protein pills and put my helmet on" | replace: "my", "your"`, "Take your protein pills and put your helmet on"},
 	{`"Take my protein pills and put my helmet on" | replace_first: "my", "your"`, "Take your protein pills and put my helmet on"},
 	{`"/my/fancy/url" | append: ".html"`, "/my/fancy/url.html"},
@@ -94,10 +286,29 @@ var filterTests = []struct {
 	{`"my great title" | capitalize`, "My great title"},
 	{`"" | capitalize`, ""},
 	{`"Parker Moore" | downcase`, "parker moore"},
+	{`"A short sentence." | reading_time`, 1},
+	{`"" | reading_time`, 1},
+	{`long_text | reading_time`, 3},
+	{`long_text | reading_time: 250`, 2},
+	{`long_text | reading_time: 500`, 1},
+	{`"<p>one</p><p>two</p> three" | reading_time: 2`, 2},
 	{`"Have you read 'James & the Giant Peach'?" | escape`, "Have you read &#39;James &amp; the Giant Peach&#39;?"},
 	{`"1 < 2 & 3" | escape_once`, "1 &lt; 2 &amp; 3"},
-	{`string_with_newlines | newline_to_br`, "<br />Hello<br />there<br />"},
+	{`"&copy; &lt;b&gt; &#169; &#xA9;" | decode_entities`, "© <b> © ©"},
+	{`"&amp;copy; caf&eacute;" | decode_entities`, "&copy; café"},
+	{`"no entities" | decode_entities`, "no entities"},
+	{`"café © 2017" | encode_entities`, "caf&#233; &#169; 2017"},
+	{`"日本" | encode_entities`, "&#26085;&#26412;"},
+	{`"plain <ascii>" | encode_entities`, "plain <ascii>"},
+	{`"café" | encode_entities | decode_entities`, "café"},
+	{`string_with_newlines | newline_to_br`, "<br />\nHello<br />\nthere<br />\n"},
+	{`string_with_crlf | newline_to_br`, "a<br />\r\nb<br />\rc"},
+	{`12 | newline_to_br`, "12"},
 	{`"1 &lt; 2 &amp; 3" | escape_once`, "1 &lt; 2 &amp; 3"},
+	{`'1 &amp; 2' | escape_once`, "1 &amp; 2"},
+	{`'1 & 2' | escape_once`, "1 &amp; 2"},
+	{`"&copy; &#169; &#xA9; &nbsp" | escape_once`, "&copy; &#169; &#xA9; &amp;nbsp"},
+	{`"<a href='x'>&</a>" | escape_once`, "&lt;a href=&#39;x&#39;&gt;&amp;&lt;/a&gt;"},
 	{`"apples, oranges, and bananas" | prepend: "Some fruit: "`, "Some fruit: apples, oranges, and bananas"},
 	{`"I strained to see the train through the rain" | remove: "rain"`, "I sted to see the t through the "},
 	{`"I strained to see the train through the rain" | remove_first: "rain"`, "I sted to see the train through the rain"},
@@ -127,13 +338,28 @@ Liquid" | slice: 2, 4`, "quid"},
 	{`"a  b" | split: ' ' | join: '-'`, "a-b"},
 	{"'a \t b' | split: ' ' | join: '-'", "a-b"},
 
+	{`"/a/b/c" | path_segments | map: "name" | join: ","`, "a,b,c"},
+	{`"/a/b/c" | path_segments | map: "url" | join: ","`, "/a,/a/b,/a/b/c"},
+	{`"/a/b/" | path_segments | map: "url" | join: ","`, "/a,/a/b"},
+	{`"a//b" | path_segments | map: "url" | join: ","`, "/a,/a/b"},
+	{`"/" | path_segments | size`, 0},
+	{`"" | path_segments | size`, 0},
+	{`"docs.api.v1" | path_segments: "." | map: "url" | join: " "`, ".docs .docs.api .docs.api.v1"},
+
 	{`"Have <em>you</em> read <strong>Ulysses</strong>?" | strip_html`, "Have you read Ulysses?"},
 	{`string_with_newlines | strip_newlines`, "Hellothere"},
+	{`string_with_crlf | strip_newlines`, "abc"},
+	{`12 | strip_newlines`, "12"},
 
 	{`"Ground control to Major Tom." | truncate: 20`, "Ground control to..."},
 	{`"Ground control to Major Tom." | truncate: 25, ", and so on"`, "Ground control, and so on"},
 	{`"Ground control to Major Tom." | truncate: 20, ""`, "Ground control to Ma"},
 	{`"Ground" | truncate: 20`, "Ground"},
+	{`"Ground" | truncate: 6`, "Ground"},
+	{`"Ground control" | truncate: 2`, "..."},
+	{`"Ground control" | truncate: -1`, "..."},
+	{`"Grüße aus Köln" | truncate: 8, "…"`, "Grüße a…"},
+	{`string_with_newlines | truncate: 8`, "\nHell..."},
 	{`"Ground control to Major Tom." | truncatewords: 3`, "Ground control to..."},
 	{`"Ground control to Major Tom." | truncatewords: 3, "--"`, "Ground control to--"},
 	{`"Ground control to Major Tom." | truncatewords: 3, ""`, "Ground control to"},
@@ -142,64 +368,189 @@ Liquid" | slice: 2, 4`, "quid"},
 	{`"  Ground" | truncatewords: 3, ""`, "  Ground"},
 	{`"" | truncatewords: 3, ""`, ""},
 	{`"  " | truncatewords: 3, ""`, "  "},
+	{`"Ground control to" | truncatewords: 3`, "Ground control to"},
+	{`"Ground control to  " | truncatewords: 3`, "Ground control to  "},
+	{`"Ground control to" | truncatewords: 0`, "Ground control to"},
+	{`string_with_newlines | truncatewords: 1`, "\nHello..."},
+	{`"<p>Ground <b>control</b> to Major Tom.</p>" | truncatewords: 2, html: true`, "<p>Ground <b>control...</b></p>"},
+	{`"<p>Ground <b>control to</b> Major Tom.</p>" | truncatewords: 2, html: true`, "<p>Ground <b>control...</b></p>"},
+	{`"<p>Ground <a href='x y z'>control</a></p> <p>to</p>" | truncatewords: 2, "", html: true`, "<p>Ground <a href='x y z'>control</a></p>"},
+	{`"<p>Ground<br/> <img src='x'> control</p>" | truncatewords: 1, html: true`, "<p>Ground...</p>"},
+	{`"<p>Ground<br/> <img src='x'> control</p>" | truncatewords: 2, html: true`, "<p>Ground<br/> <img src='x'> control</p>"},
+	{`"<div><p>One <!-- two --> three</p></div>" | truncatewords: 1, html: true`, "<div><p>One...</p></div>"},
+	{`"<p>Ground <b>control</b></p>" | truncatewords: 2, html: true`, "<p>Ground <b>control</b></p>"},
+	{`"<p>Ground <b>control</b></p>" | truncatewords: 1, html: false`, "<p>Ground..."},
+	{`"plain words here" | truncatewords: 2, html: true`, "plain words..."},
 
 	{`"Parker Moore" | upcase`, "PARKER MOORE"},
 	{`"          So much room for activities!          " | strip`, "So much room for activities!"},
 	{`"          So much room for activities!          " | lstrip`, "So much room for activities!          "},
 	{`"          So much room for activities!          " | rstrip`, "          So much room for activities!"},
+	{`unicode_whitespace | strip`, "wide\u00a0space"},
+	{`unicode_whitespace | lstrip`, "wide\u00a0space \u2028\u0085"},
+	{`unicode_whitespace | rstrip`, "\u00a0\u2003\u3000 wide\u00a0space"},
+	{`ascii_whitespace | strip`, "x"},
 
 	{`"%27Stop%21%27+said+Fred" | url_decode`, "'Stop!' said Fred"},
 	{`"john@liquid.com" | url_encode`, "john%40liquid.com"},
 	{`"Tetsuro Takara" | url_encode`, "Tetsuro+Takara"},
 
 	// number filters
-	{`-17 | abs`, 17.0},
-	{`4 | abs`, 4.0},
+	{`-17 | abs`, 17},
+	{`4 | abs`, 4},
+	{`-17.5 | abs`, 17.5},
+	{`4.0 | abs`, 4.0},
 	{`"-19.86" | abs`, 19.86},
 
+	{`-3 | at_least: 0`, 0},
+	{`5 | at_least: 0`, 5},
+	{`5 | at_least: 5`, 5},
+	{`"4.5" | at_least: 5`, 5},
+	{`"4.5" | at_least: 3`, 4.5},
+	{`1.5 | at_least: 2.5`, 2.5},
+	{`nil | at_least: 1`, 1},
+	{`nil | at_least: -1`, 0},
+	{`150 | at_most: 100`, 100},
+	{`50 | at_most: 100`, 50},
+	{`"101" | at_most: 100`, 100},
+	{`"99.5" | at_most: 100`, 99.5},
+	{`-1 | at_most: 0`, -1},
+	{`nil | at_most: 1`, 0},
+
 	{`1.2 | ceil`, 2},
 	{`2.0 | ceil`, 2},
 	{`183.357 | ceil`, 184},
 	{`"3.5" | ceil`, 4},
+	{`"2.3" | ceil`, 3},
+	{`-1.5 | ceil`, -1},
+	{`"-1.5" | ceil`, -1},
+	{`1.2 | ceil | plus: 1`, 3},
+	{`4 | ceil`, 4},
 
 	{`1.2 | floor`, 1},
 	{`2.0 | floor`, 2},
 	{`183.357 | floor`, 183},
+	{`"2.3" | floor`, 2},
+	{`-1.5 | floor`, -2},
+	{`"-1.5" | floor`, -2},
+	{`3.9 | floor | divided_by: 2`, 1},
+	{`4 | floor`, 4},
 
-	{`4 | plus: 2`, 6.0},
+	{`4 | plus: 2`, 6},
 	{`183.357 | plus: 12`, 195.357},
+	{`3.0 | plus: 1`, 4.0},
+	{`"3" | plus: 1`, 4},
+	{`"3.5" | plus: 1`, 4.5},
 
-	{`4 | minus: 2`, 2.0},
-	{`16 | minus: 4`, 12.0},
+	{`4 | minus: 2`, 2},
+	{`16 | minus: 4`, 12},
 	{`183.357 | minus: 12`, 171.357},
+	{`4 | minus: 1.5`, 2.5},
 
-	{`3 | times: 2`, 6.0},
-	{`24 | times: 7`, 168.0},
+	{`3 | times: 2`, 6},
+	{`24 | times: 7`, 168},
 	{`183.357 | times: 12`, 2200.284},
-
-	{`3 | modulo: 2`, 1.0},
-	{`24 | modulo: 7`, 3.0},
-	// {`183.357 | modulo: 12 | `, 3.357}, // TODO test suit use inexact
+	{`2 | times: 2.0`, 4.0},
+
+	{`3 | modulo: 2`, 1},
+	{`24 | modulo: 7`, 3},
+	{`24 | modulo: 6`, 0},
+	{`-7 | modulo: 3`, 2},
+	{`7 | modulo: -3`, -2},
+	{`183.357 | modulo: 12`, 3.357},
+	{`10.1 | modulo: 3`, 1.1},
+	{`0.3 | modulo: 0.1`, 0.0},
+	{`-183.357 | modulo: 12`, 8.643},
+	{`5.25 | modulo: -2`, -0.75},
+	{`7 | modulo: 2.5`, 2.0},
+	{`7.5 | modulo: 2`, 1.5},
+	{`-7.5 | modulo: 2`, 0.5},
+	{`6.0 | modulo: 3`, 0.0},
+
+	// string operands
+	{`"5" | plus: "3"`, 8},
+	{`"5" | plus: "0.5"`, 5.5},
+	{`" 5 " | plus: 3`, 8},
+	{`"five" | plus: 3`, 3},
+	{`nil | plus: "3"`, 3},
+	{`"5" | minus: "3"`, 2},
+	{`5 | minus: "1.5"`, 3.5},
+	{`"abc" | minus: 1`, -1},
+	{`"5" | times: "3"`, 15},
+	{`"2.5" | times: "2"`, 5.0},
+	{`"5" | times: "x"`, 0},
+	{`"7" | modulo: "4"`, 3},
+	{`"7.5" | modulo: 2`, 1.5},
+	{`"-4" | abs`, 4},
+	{`"x" | abs`, 0},
+	{`nil | abs`, 0},
+	{`"3" | at_least: "4"`, 4},
+	{`"x" | at_most: "4"`, 0},
+	{`"10" | divided_by: "4"`, 2},
+	{`"10" | divided_by: "2.5"`, 4.0},
+	{`10 | divided_by: " 4 "`, 2},
+	{`"abc" | divided_by: 2`, 0},
+	{`nil | divided_by: "2"`, 0},
 
 	{`16 | divided_by: 4`, 4},
 	{`5 | divided_by: 3`, 1},
 	{`20 | divided_by: 7`, 2},
 	{`20 | divided_by: 7.0`, 2.857142857142857},
-	{`20 | divided_by: 's'`, nil},
-
-	{`1.2 | round`, 1.0},
-	{`2.7 | round`, 3.0},
+	{`5 | divided_by: 2`, 2},
+	{`-5 | divided_by: 2`, -3},
+	{`5 | divided_by: 2.0`, 2.5},
+	{`5.0 | divided_by: 2`, 2.5},
+	{`"5" | divided_by: 2`, 2},
+
+	{`1.2 | round`, 1},
+	{`2.7 | round`, 3},
 	{`183.357 | round: 2`, 183.36},
+	{`2.567 | round: 2`, 2.57},
+	{`2.5 | round: 0`, 3},
+	{`-2.5 | round`, -3},
+	{`-2.567 | round: 1`, -2.6},
+	{`1234 | round: -2`, 1200},
+	{`"2.567" | round: 2`, 2.57},
+	{`183.357 | round | plus: 1`, 184},
+	{`4 | round`, 4},
+	{`2.567 | round: 1 | plus: 1`, 3.6},
+
+	{`1 | percent_of: 4`, 25},
+	{`3 | percent_of: 2`, 150},
+	{`1 | percent_of: 3`, 33},
+	{`2 | percent_of: 3`, 67},
+	{`1 | percent_of: 3, 2`, 33.33},
+	{`"1.5" | percent_of: 4, 1`, 37.5},
+	{`1 | percent_of: 8`, 13},
+	{`-1 | percent_of: 8`, -13},
+	{`-1 | percent_of: 8, 1`, -12.5},
+	{`-2 | percent_of: 3`, -67},
+	{`1 | percent_of: 0`, 0},
+	{`1 | percent_of: 0, 2`, 0.0},
 
 	// Jekyll extensions; added here for convenient testing
 	// TODO add this just to the test environment
-	{`map | inspect`, `{"a":1}`},
+	{`map | inspect`, `map[string]interface {}{"a": int(1)}`},
+	{`map | debug`, `map[string]interface {}{"a": int(1)}`},
+	{`nested | inspect`, `map[string]interface {}{"list": []interface {}{int(1), "x", nil, float64(2.5)}, "m": map[string]int{"a": 1, "b": 2}, "ok": bool(true)}`},
+	{`nested.m | inspect`, `map[string]int{"a": 1, "b": 2}`},
+	{`nil | inspect`, `nil`},
+	{`missing | inspect`, `nil`},
+	{`1 | inspect`, `int(1)`},
+	{`"1" | inspect`, `"1"`},
 	{`1 | type`, `int`},
 	{`"1" | type`, `string`},
 }
 
 var filterTestBindings = map[string]interface{}{
-	"empty_array":     []interface{}{},
+	"ascii_whitespace":   "\t\n\r\v\f x \t",
+	"unicode_whitespace": "\u00a0\u2003\u3000 wide\u00a0space \u2028\u0085",
+	"empty_array":        []interface{}{},
+	"nested": map[string]interface{}{
+		"list": []interface{}{1, "x", nil, 2.5},
+		"m":    map[string]int{"b": 2, "a": 1},
+		"ok":   true,
+	},
 	"empty_map":       map[string]interface{}{},
 	"empty_map_slice": yaml.MapSlice{},
 	"map": map[string]interface{}{
@@ -224,9 +575,38 @@ var filterTestBindings = map[string]interface{}{
 		{"weight": 3},
 		{"weight": nil},
 	},
+	"sort_ties": []map[string]interface{}{
+		{"name": "a", "weight": 2, "group": "x"},
+		{"name": "b", "weight": 3, "group": "X"},
+		{"name": "c", "weight": 1, "group": "y"},
+		{"name": "d", "weight": 2, "group": "x"},
+		{"name": "e", "weight": 1, "group": "X"},
+		{"name": "f", "weight": 3},
+		{"name": "g", "group": "x"},
+	},
+	"sort_tie_strings":     []string{"a", "b", "A", "B", "a"},
 	"string_with_newlines": "\nHello\nthere\n",
+	"string_with_crlf":     "a\r\nb\rc",
+	"long_text":            "<p>" + strings.Repeat("word ", 500) + "</p>",
 	"dup_ints":             []int{1, 2, 1, 3},
 	"dup_strings":          []string{"one", "two", "one", "three"},
+	"dup_ids": []map[string]interface{}{
+		{"id": 1, "name": "a"},
+		{"id": 2, "name": "b"},
+		{"id": 1.0, "name": "c"},
+		{"name": "d"},
+		{"id": 2, "name": "e"},
+	},
+
+	"defaults": map[string]interface{}{
+		"title":  "Untitled",
+		"color":  "blue",
+		"layout": map[string]interface{}{"sidebar": true, "width": 80},
+	},
+	"overrides": map[string]interface{}{
+		"color":  "red",
+		"layout": map[string]interface{}{"width": 100},
+	},
 
 	// for examples from liquid docs
 	"animals": []string{"zebra", "octopus", "giraffe", "Sally Snake"},
@@ -237,6 +617,37 @@ var filterTestBindings = map[string]interface{}{
 	"page": map[string]interface{}{
 		"title": "Introduction",
 	},
+	"csv_row":  []interface{}{"a", "b,c", `say "hi"`, "line 1\nline 2", 1.5, nil},
+	"csv_rows": [][]interface{}{{"name", "qty"}, {"apple", 3}, {"banana, ripe", 12}},
+	"products": []map[string]interface{}{
+		{"title": "a", "price": 10, "in_stock": true},
+		{"title": "b", "price": 20, "in_stock": true},
+		{"title": "c", "price": 30, "in_stock": false},
+		{"title": "d", "price": 40, "in_stock": true},
+	},
+	"docs": []map[string]interface{}{
+		{"title": "a", "draft": true},
+		{"title": "b", "draft": false},
+		{"title": "c", "hidden": true},
+		{"title": "d"},
+	},
+	"flags": []map[string]interface{}{
+		{"title": "true", "a": true},
+		{"title": "false", "a": false},
+		{"title": "0", "a": 0},
+		{"title": "empty", "a": ""},
+		{"title": "string", "a": "false"},
+		{"title": "nil", "a": nil},
+		{"title": "missing"},
+	},
+	"events": []map[string]interface{}{
+		{"title": "a", "date": "2017-07-01"},
+		{"title": "b", "date": "2017-07-01"},
+		{"title": "c", "date": "2017-07-02"},
+		{"title": "d", "date": "2017-07-01"},
+		{"title": "e", "date": "2017-07-03"},
+		{"title": "f", "date": "2017-07-03"},
+	},
 	"pages": []map[string]interface{}{
 		{"name": "page 1", "category": "business"},
 		{"name": "page 2", "category": "celebrities"},
@@ -255,6 +666,34 @@ var filterTestBindings = map[string]interface{}{
 	},
 }
 
+var filterErrorTests = []struct{ in, expected string }{
+	{`"2017-07-09" | parse_date: "%d/%m/%Y"`, "can't parse"},
+	{`"2017-07-09" | parse_date: "%Y-%m-%d", "Nowhere/Special"`, "unknown time zone"},
+	{`"2017-07-09" | parse_date: "%Q"`, "unsupported directive"},
+	{`"at 2017" | parse_date: "at %Y"`, "unsupported literal"},
+	{`"text" | reading_time: 0`, "words per minute must be positive"},
+	{`defaults | merge: "text"`, "merge requires two maps"},
+	{`"text" | merge: defaults`, "merge requires two maps"},
+	{`"http://[::1" | normalize_url`, "missing ']' in host"},
+	{`products | take_while: "p", "p.in_stock ==" | size`, "syntax error"},
+	{`docs | where: "draft", true, 1`, "wrong number of arguments (given 3, expected 1 or 2)"},
+	{`fruits | in_groups_of: 0`, "in_groups_of requires a positive group size; got 0"},
+	{`fruits | first: -1`, "first requires a non-negative count; got -1"},
+	{`fruits | last: 1, 2`, "wrong number of arguments (given 2, expected 0 or 1)"},
+	{`dup_ids | uniq: "id", "name"`, "wrong number of arguments (given 2, expected 0 or 1)"},
+	{`fruits | in_groups_of: -2`, "in_groups_of requires a positive group size; got -2"},
+	{`fruits | in_groups_of: 2, "x", "y"`, "wrong number of arguments (given 3, expected 1 or 2)"},
+	{`csv_row | to_csv: "::"`, "to_csv delimiter must be a single character"},
+	{`5 | divided_by: 0`, "divided by 0"},
+	{`5.0 | divided_by: 0.0`, "divided by 0"},
+	{`5 | modulo: 0`, "divided by 0"},
+	{`5.5 | modulo: 0.0`, "divided by 0"},
+	{`5 | modulo: "x"`, "divided by 0"},
+	{`"10" | divided_by: "x"`, "divided by 0"},
+	{`20 | divided_by: 's'`, "divided by 0"},
+	{`20 | divided_by: fruits`, "can't convert"},
+}
+
 func TestFilters(t *testing.T) {
 	require.NoError(t, os.Setenv("TZ", "America/New_York"))
 
@@ -278,6 +717,96 @@ func TestFilters(t *testing.T) {
 	}
 }
 
+func TestFilters_errors(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	context := expressions.NewContext(filterTestBindings, cfg)
+
+	for i, test := range filterErrorTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			_, err := expressions.EvaluateString(test.in, context)
+			require.Errorf(t, err, test.in)
+			require.Containsf(t, err.Error(), test.expected, test.in)
+		})
+	}
+}
+
+func TestFilters_debug(t *testing.T) {
+	type label string
+	type item struct {
+		Name   string
+		Tags   []label
+		Parent *item
+		Extra  interface{}
+		hidden int
+	}
+	cyclic := map[string]interface{}{"name": "loop"}
+	cyclic["self"] = cyclic
+	list := []interface{}{1, nil}
+	list[1] = list
+	parent := &item{Name: "p"}
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, `nil`},
+		{[]int(nil), `([]int)(nil)`},
+		{(*item)(nil), `(*filters.item)(nil)`},
+		{label("x"), `filters.label("x")`},
+		{[]interface{}{label("x"), "y"}, `[]interface {}{filters.label("x"), "y"}`},
+		{item{Name: "c", Tags: []label{"a"}, Parent: parent, Extra: 1}, `filters.item{Name: "c", Tags: []filters.label{"a"}, Parent: &filters.item{Name: "p", Tags: ([]filters.label)(nil), Parent: (*filters.item)(nil), Extra: nil}, Extra: int(1)}`},
+		{time.Date(2017, 7, 9, 15, 0, 0, 0, time.UTC), `time.Time("2017-07-09 15:00:00 +0000 UTC")`},
+		{cyclic, `map[string]interface {}{"name": "loop", "self": <cycle>}`},
+		{list, `[]interface {}{int(1), <cycle>}`},
+		{[]interface{}{parent, parent}, `[]interface {}{&filters.item{Name: "p", Tags: ([]filters.label)(nil), Parent: (*filters.item)(nil), Extra: nil}, &filters.item{Name: "p", Tags: ([]filters.label)(nil), Parent: (*filters.item)(nil), Extra: nil}}`},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			require.Equal(t, test.expected, debugFilter(test.value))
+		})
+	}
+
+	// inspect uses the same formatting
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	context := expressions.NewContext(map[string]interface{}{"cyclic": cyclic}, cfg)
+	actual, err := expressions.EvaluateString(`cyclic | inspect`, context)
+	require.NoError(t, err)
+	require.Equal(t, `map[string]interface {}{"name": "loop", "self": <cycle>}`, actual)
+}
+
+func TestFilters_arrayMutation(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	list := []interface{}{"a", "b", "c"}
+	context := expressions.NewContext(map[string]interface{}{"list": list}, cfg)
+
+	for _, expr := range []string{`list | push: "d"`, `list | pop`, `list | shift`, `list | unshift: "z"`} {
+		result, err := expressions.EvaluateString(expr, context)
+		require.NoErrorf(t, err, expr)
+		result.([]interface{})[0] = "changed"
+		require.Equalf(t, []interface{}{"a", "b", "c"}, list, expr)
+	}
+}
+
+func TestParseDateFilter_comparison(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	context := expressions.NewContext(filterTestBindings, cfg)
+
+	value, err := expressions.EvaluateString(`"2017-07-09" | parse_date: "%Y-%m-%d", "UTC"`, context)
+	require.NoError(t, err)
+	require.Equal(t, timeMustParse("2017-07-09T00:00:00Z"), value)
+
+	context.Set("t", value)
+	value, err = expressions.EvaluateString(`t < article.published_at`, context)
+	require.NoError(t, err)
+	require.Equal(t, false, value)
+	value, err = expressions.EvaluateString(`article.published_at < t`, context)
+	require.NoError(t, err)
+	require.Equal(t, true, value)
+}
+
 func timeMustParse(s string) time.Time {
 	t, err := time.Parse(time.RFC3339, s)
 	if err != nil {
@@ -285,3 +814,48 @@ func timeMustParse(s string) time.Time {
 	}
 	return t
 }
+
+func TestFilters_translate(t *testing.T) {
+	cfg := expressions.NewConfig()
+	AddStandardFilters(&cfg)
+	cfg.AddTranslations("en", map[string]string{
+		"cart.count": "%{count} items in your cart",
+		"cart.title": "Cart",
+		"greeting":   "Hello, %{name}! %{missing}",
+	})
+	cfg.AddTranslations("fr", map[string]string{
+		"cart.count": "%{count} articles dans votre panier",
+	})
+	cfg.AddTranslations("fr-CA", map[string]string{
+		"cart.title": "Panier",
+	})
+	bindings := map[string]interface{}{"cart": map[string]interface{}{"size": 3}}
+	tests := []struct{ locale, in, expected string }{
+		{"", `"cart.count" | t: count: cart.size`, "3 items in your cart"},
+		{"", `"greeting" | t: name: "Ann"`, "Hello, Ann! %{missing}"},
+		{"", `"cart.missing" | t`, "cart.missing"},
+		{"fr", `"cart.count" | t: count: cart.size`, "3 articles dans votre panier"},
+		{"fr", `"cart.title" | t`, "Cart"},
+		{"fr-CA", `"cart.title" | t`, "Panier"},
+		{"fr-CA", `"cart.count" | t: count: 1`, "1 articles dans votre panier"},
+		{"de", `"cart.title" | t`, "Cart"},
+		{"de", `"cart.missing" | t`, "cart.missing"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			delete(cfg.Settings, "locale")
+			if test.locale != "" {
+				cfg.Settings["locale"] = test.locale
+			}
+			actual, err := expressions.EvaluateString(test.in, expressions.NewContext(bindings, cfg))
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, actual, test.in)
+		})
+	}
+
+	cfg.Settings["locale"] = "de"
+	cfg.Settings["default_locale"] = "fr"
+	actual, err := expressions.EvaluateString(`"cart.count" | t: count: 2`, expressions.NewContext(bindings, cfg))
+	require.NoError(t, err)
+	require.Equal(t, "2 articles dans votre panier", actual)
+}
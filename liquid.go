@@ -8,6 +8,8 @@ The liquid package itself is versioned in gopkg.in. Subpackages have no compatib
 package liquid
 
 import (
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/parser"
 	"github.com/osteele/liquid/render"
 	"github.com/osteele/liquid/tags"
 )
@@ -33,6 +35,18 @@ type SourceError interface {
 	LineNumber() int
 }
 
+// A FilterContext gives a filter access to the engine settings and the template variables.
+// See Engine.RegisterFilter.
+type FilterContext = expressions.FilterContext
+
+// A ParseError is a SourceError that occurs while a template is parsed; for example a syntax
+// error or an undefined tag. Use errors.As to retrieve it from the error returned by ParseTemplate.
+type ParseError = parser.ParseError
+
+// A RenderError is a SourceError that occurs while a template is rendered; for example an error
+// returned by a filter. Use errors.As to retrieve it from the error returned by Render.
+type RenderError = render.RenderError
+
 // IterationKeyedMap returns a map whose {% for %} tag iteration values are its keys, instead of [key, value] pairs.
 // Use this to create a Go map with the semantics of a Ruby struct drop.
 func IterationKeyedMap(m map[string]interface{}) tags.IterationKeyedMap {
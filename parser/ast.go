@@ -10,17 +10,34 @@ type ASTNode interface {
 	SourceText() string
 }
 
+// A TagNode is an ASTTag, or an ASTBlock for a block or for one of its clauses; for
+// example {% else %}. Tools that analyze or rewrite templates can use it to read a tag's
+// name and its raw argument text, without scanning the tag source.
+type TagNode interface {
+	ASTNode
+	// TagName returns the tag name; for example "for" in {% for a in b %}.
+	TagName() string
+	// TagArgs returns the text that follows the tag name, without surrounding
+	// whitespace; for example "a in b" in {% for a in b %}.
+	TagArgs() string
+}
+
 // ASTBlock represents a {% tag %}…{% endtag %}.
 type ASTBlock struct {
 	Token
 	syntax  BlockSyntax
 	Body    []ASTNode   // Body is the nodes before the first branch
 	Clauses []*ASTBlock // E.g. else and elseif w/in an if
+	// InnerSource is the unparsed source between the start and end tags of a block,
+	// including its clauses. It's empty for a clause.
+	InnerSource string
 }
 
 // ASTRaw holds the text between the start and end of a raw tag.
 type ASTRaw struct {
 	Slices []string
+	// TrimLeft and TrimRight trim the whitespace outside the block; from {%- raw %} and {% endraw -%}
+	TrimLeft, TrimRight bool
 	sourcelessNode
 }
 
@@ -29,6 +46,18 @@ type ASTTag struct {
 	Token
 }
 
+// TagName returns the name of the tag that begins the block or clause.
+func (n *ASTBlock) TagName() string { return n.Name }
+
+// TagArgs returns the argument text of the tag that begins the block or clause.
+func (n *ASTBlock) TagArgs() string { return n.Args }
+
+// TagName returns the tag name.
+func (n *ASTTag) TagName() string { return n.Name }
+
+// TagArgs returns the tag's argument text.
+func (n *ASTTag) TagArgs() string { return n.Args }
+
 // ASTText is a text span, that is rendered verbatim.
 type ASTText struct {
 	Token
@@ -46,6 +75,25 @@ type ASTSeq struct {
 	sourcelessNode
 }
 
+// Walk calls fn for node and each of its descendants, in source order. The clauses of a
+// block follow its body, and each clause is followed by its own body.
+func Walk(node ASTNode, fn func(ASTNode)) {
+	fn(node)
+	switch n := node.(type) {
+	case *ASTSeq:
+		for _, c := range n.Children {
+			Walk(c, fn)
+		}
+	case *ASTBlock:
+		for _, c := range n.Body {
+			Walk(c, fn)
+		}
+		for _, clause := range n.Clauses {
+			Walk(clause, fn)
+		}
+	}
+}
+
 // It shouldn't be possible to get an error from one of these node types.
 // If it is, this needs to be re-thought to figure out where the source
 // location comes from.
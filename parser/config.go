@@ -7,9 +7,27 @@ type Config struct {
 	expressions.Config
 	Grammar Grammar
 	Delims  []string
+
+	// MaxTemplateSize, if positive, is the maximum length in bytes of a template source.
+	// Parse returns an error, without scanning the source, if the source is longer.
+	MaxTemplateSize int
+
+	// TrimBlocks removes the first newline after a {% tag %}.
+	TrimBlocks bool
+
+	// LstripBlocks removes the spaces and tabs before a {% tag %} that begins a line.
+	LstripBlocks bool
+}
+
+// Clone returns a copy of c whose expression configuration and delimiters can be
+// modified without affecting c. The copy shares c's Grammar.
+func (c Config) Clone() Config {
+	c.Config = c.Config.Clone()
+	c.Delims = append([]string(nil), c.Delims...)
+	return c
 }
 
 // NewConfig creates a parser Config.
 func NewConfig(g Grammar) Config {
-	return Config{Grammar: g}
+	return Config{Config: expressions.NewConfig(), Grammar: g}
 }
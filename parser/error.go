@@ -16,13 +16,47 @@ type Locatable interface {
 	SourceText() string
 }
 
-// Errorf creates a parser.Error.
-func Errorf(loc Locatable, format string, a ...interface{}) *sourceLocError { // nolint: golint
-	return &sourceLocError{loc.SourceLocation(), loc.SourceText(), fmt.Sprintf(format, a...), nil}
+// A LocatedError is an error that is associated with a location in the template source.
+// ParseError and render.RenderError embed it.
+type LocatedError struct {
+	SourceLoc
+	// Context is the source text where the error occurred. Error reports this if the path is unknown.
+	Context string
+	Message string
+	// Tag is the name of the tag where the error occurred, if any.
+	Tag string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// A ParseError is an error that occurs while a template is parsed or compiled; for example a
+// syntax error or an undefined tag.
+type ParseError struct {
+	LocatedError
+}
+
+// NewLocatedError creates a LocatedError at loc, with a message formatted from format and a,
+// and an optional underlying error.
+func NewLocatedError(loc Locatable, cause error, format string, a ...interface{}) LocatedError {
+	le := LocatedError{loc.SourceLocation(), loc.SourceText(), fmt.Sprintf(format, a...), "", cause}
+	if t, ok := loc.(interface{ tagName() string }); ok {
+		le.Tag = t.tagName()
+	}
+	return le
+}
+
+// Errorf creates a *ParseError.
+func Errorf(loc Locatable, format string, a ...interface{}) *ParseError { // nolint: golint
+	return &ParseError{NewLocatedError(loc, nil, format, a...)}
 }
 
-// WrapError wraps its argument in a parser.Error if this argument is not already a parser.Error and is not locatable.
+// WrapError wraps its argument in a *ParseError if this argument is not already a parser.Error and is not locatable.
 func WrapError(err error, loc Locatable) Error {
+	return WrapErrorWith(err, loc, func(le LocatedError) Error { return &ParseError{le} })
+}
+
+// WrapErrorWith is WrapError, except that it uses wrap to create the error from its location information.
+func WrapErrorWith(err error, loc Locatable, wrap func(LocatedError) Error) Error {
 	if err == nil {
 		return nil
 	}
@@ -36,38 +70,41 @@ func WrapError(err error, loc Locatable) Error {
 			err = e.Cause()
 		}
 	}
-	re := Errorf(loc, "%s", err)
-	re.cause = err
-	return re
+	return wrap(NewLocatedError(loc, err, "%s", err))
 }
 
-type sourceLocError struct {
-	SourceLoc
-	context string
-	message string
-	cause   error
+// Cause returns the underlying error, if any.
+func (e *LocatedError) Cause() error {
+	return e.Err
 }
 
-func (e *sourceLocError) Cause() error {
-	return e.cause
+// Unwrap returns the underlying error, if any, for use with errors.Is and errors.As.
+func (e *LocatedError) Unwrap() error {
+	return e.Err
 }
 
-func (e *sourceLocError) Path() string {
+// Path returns the pathname of the template source, if this is known.
+func (e *LocatedError) Path() string {
 	return e.Pathname
 }
 
-func (e *sourceLocError) LineNumber() int {
+// LineNumber returns the one-based line number of the error, or zero if this is unknown.
+func (e *LocatedError) LineNumber() int {
 	return e.LineNo
 }
 
-func (e *sourceLocError) Error() string {
+// Error formats the error as "path:line:col: message" if the path is known, and
+// as "Liquid error (line N): message in source" otherwise.
+func (e *LocatedError) Error() string {
+	if e.Pathname != "" {
+		if e.LineNo <= 0 {
+			return fmt.Sprintf("%s: %s", e.Pathname, e.Message)
+		}
+		return fmt.Sprintf("%s: %s", e.SourceLoc, e.Message)
+	}
 	line := ""
 	if e.LineNo > 0 {
-		line = fmt.Sprintf(" (line %d)", e.LineNo)
-	}
-	locative := " in " + e.context
-	if e.Pathname != "" {
-		locative = " in " + e.Pathname
+		line = fmt.Sprintf(" (%s)", e.SourceLoc)
 	}
-	return fmt.Sprintf("Liquid error%s: %s%s", line, e.message, locative)
+	return fmt.Sprintf("Liquid error%s: %s in %s", line, e.Message, e.Context)
 }
@@ -4,16 +4,52 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/osteele/liquid/expressions"
 )
 
 // Parse parses a source template. It returns an AST root, that can be compiled and evaluated.
 func (c Config) Parse(source string, loc SourceLoc) (ASTNode, Error) {
+	if c.MaxTemplateSize > 0 && len(source) > c.MaxTemplateSize {
+		return nil, Errorf(Token{SourceLoc: loc, Source: "template"}, "template source is %d bytes, which exceeds the maximum of %d bytes", len(source), c.MaxTemplateSize)
+	}
 	tokens := Scan(source, loc, c.Delims)
+	if c.TrimBlocks || c.LstripBlocks {
+		trimBlockWhitespace(tokens, c.TrimBlocks, c.LstripBlocks)
+	}
 	return c.parseTokens(tokens)
 }
 
+// trimBlockWhitespace implements TrimBlocks and LstripBlocks, by editing the text
+// tokens that are adjacent to tag tokens. The text inside {% raw %} is left as is.
+func trimBlockWhitespace(tokens []Token, trim, lstrip bool) {
+	inRaw := false
+	for i, tok := range tokens {
+		if tok.Type != TagTokenType || (inRaw && !isRawEnd(tok)) {
+			continue
+		}
+		if lstrip && i > 0 && tokens[i-1].Type == TextTokenType && !isRawEnd(tok) {
+			prev := &tokens[i-1]
+			j := strings.LastIndexByte(prev.Source, '\n')
+			if tail := prev.Source[j+1:]; (j >= 0 || i == 1) && strings.Trim(tail, " \t") == "" {
+				prev.Source = prev.Source[:j+1]
+			}
+		}
+		inRaw = tok.Name == "raw"
+		if trim && !inRaw && i+1 < len(tokens) && tokens[i+1].Type == TextTokenType {
+			next := &tokens[i+1]
+			for _, nl := range []string{"\n", "\r\n"} {
+				if strings.HasPrefix(next.Source, nl) {
+					next.Source = next.Source[len(nl):]
+					next.SourceLoc = next.SourceLoc.advance("\n")
+					break
+				}
+			}
+		}
+	}
+}
+
 // Parse creates an AST from a sequence of tokens.
 func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { // nolint: gocyclo
 	// a stack of control tag state, for matching nested {%if}{%endif%} etc.
@@ -21,30 +57,42 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { // nolint: gocycl
 		syntax BlockSyntax
 		node   *ASTBlock
 		ap     *[]ASTNode
+		start  int // the index of the token that starts the block
 	}
 	var (
-		g         = c.Grammar
-		root      = &ASTSeq{}      // root of AST; will be returned
-		ap        = &root.Children // newly-constructed nodes are appended here
-		sd        BlockSyntax      // current block syntax definition
-		bn        *ASTBlock        // current block node
-		stack     []frame          // stack of blocks
-		rawTag    *ASTRaw          // current raw tag
-		inComment = false
-		inRaw     = false
+		g       = c.Grammar
+		root    = &ASTSeq{}      // root of AST; will be returned
+		ap      = &root.Children // newly-constructed nodes are appended here
+		sd      BlockSyntax      // current block syntax definition
+		bn      *ASTBlock        // current block node
+		stack   []frame          // stack of blocks
+		rawTag  *ASTRaw          // current raw tag
+		raw     *Token           // the start tag of the current raw tag
+		comment *Token           // outermost enclosing comment tag
+		depth   int              // comment nesting depth
 	)
-	for _, tok := range tokens {
+	for i, tok := range tokens {
 		switch {
 		// The parser needs to know about comment and raw, because tags inside
-		// needn't match each other e.g. {%comment%}{%if%}{%endcomment%}
-		// TODO is this true?
-		case inComment:
-			if tok.Type == TagTokenType && tok.Name == "endcomment" {
-				inComment = false
+		// needn't match each other e.g. {%comment%}{%if%}{%endcomment%}.
+		// Comments nest, so that a comment can comment out a template that has one.
+		case comment != nil:
+			if tok.Type == TagTokenType {
+				switch tok.Name {
+				case "comment":
+					depth++
+				case "endcomment":
+					depth--
+					if depth == 0 {
+						comment = nil
+					}
+				}
 			}
-		case inRaw:
-			if tok.Type == TagTokenType && tok.Name == "endraw" {
-				inRaw = false
+		case raw != nil:
+			if isRawEnd(tok) {
+				trimRawBody(rawTag, raw.TrimRight, tok.TrimLeft)
+				rawTag.TrimRight = tok.TrimRight
+				raw = nil
 			} else {
 				rawTag.Slices = append(rawTag.Slices, tok.Source)
 			}
@@ -60,10 +108,10 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { // nolint: gocycl
 			if cs, ok := g.BlockSyntax(tok.Name); ok {
 				switch {
 				case tok.Name == "comment":
-					inComment = true
+					comment, depth = &tokens[i], 1
 				case tok.Name == "raw":
-					inRaw = true
-					rawTag = &ASTRaw{}
+					raw = &tokens[i]
+					rawTag = &ASTRaw{TrimLeft: tok.TrimLeft}
 					*ap = append(*ap, rawTag)
 				case cs.RequiresParent() && (sd == nil || !cs.CanHaveParent(sd)):
 					suffix := ""
@@ -73,7 +121,7 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { // nolint: gocycl
 					return nil, Errorf(tok, "%s not inside %s%s", tok.Name, strings.Join(cs.ParentTags(), " or "), suffix)
 				case cs.IsBlockStart():
 					push := func() {
-						stack = append(stack, frame{syntax: sd, node: bn, ap: ap})
+						stack = append(stack, frame{syntax: sd, node: bn, ap: ap, start: i})
 						sd, bn = cs, &ASTBlock{Token: tok, syntax: cs}
 						*ap = append(*ap, bn)
 					}
@@ -87,6 +135,7 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { // nolint: gocycl
 					pop := func() {
 						f := stack[len(stack)-1]
 						stack = stack[:len(stack)-1]
+						bn.InnerSource = tokenSources(tokens[f.start+1 : i])
 						sd, bn, ap = f.syntax, f.node, f.ap
 					}
 					pop()
@@ -98,8 +147,45 @@ func (c Config) parseTokens(tokens []Token) (ASTNode, Error) { // nolint: gocycl
 			}
 		}
 	}
+	if comment != nil {
+		return nil, Errorf(comment, "unterminated %q block", comment.Name)
+	}
+	if raw != nil {
+		return nil, Errorf(raw, "unterminated %q block", raw.Name)
+	}
 	if bn != nil {
 		return nil, Errorf(bn, "unterminated %q block", bn.Name)
 	}
 	return root, nil
 }
+
+// tokenSources returns the concatenated source of tokens.
+func tokenSources(tokens []Token) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteString(tok.Source)
+	}
+	return b.String()
+}
+
+// isRawEnd returns true if tok ends a raw block. Within the block, {% endraw %} with
+// arguments is text.
+func isRawEnd(tok Token) bool {
+	return tok.Type == TagTokenType && tok.Name == "endraw" && tok.Args == ""
+}
+
+// trimRawBody implements {% raw -%} and {%- endraw %}, by trimming the whitespace at
+// the start or end of the block's text.
+func trimRawBody(n *ASTRaw, start, end bool) {
+	if !start && !end {
+		return
+	}
+	body := strings.Join(n.Slices, "")
+	if start {
+		body = strings.TrimLeftFunc(body, unicode.IsSpace)
+	}
+	if end {
+		body = strings.TrimRightFunc(body, unicode.IsSpace)
+	}
+	n.Slices = []string{body}
+}
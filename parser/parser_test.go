@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -31,6 +32,10 @@ func (g blockSyntaxFake) TagName() string      { return string(g) }
 var parseErrorTests = []struct{ in, expected string }{
 	{"{% if test %}", `unterminated "if" block`},
 	{"{% if test %}{% endunless %}", "not inside unless"},
+	{"{% comment %}{% if test %}", `unterminated "comment" block`},
+	{"{% comment %}{% comment %}{% endcomment %}", `unterminated "comment" block`},
+	{"{% raw %}{{ x }}", `unterminated "raw" block`},
+	{"{% raw %}{% endraw x %}", `unterminated "raw" block`},
 	// TODO tag syntax could specify statement type to catch these in parser
 	// {"{{ syntax error }}", "syntax error"},
 	// {"{% for syntax error %}{% endfor %}", "syntax error"},
@@ -45,6 +50,8 @@ var parserTests = []struct{ in string }{
 	{`{% if true %}{% raw %}{% endraw %}{% endif %}`},
 
 	{`{% comment %}{% if true %}{% endcomment %}`},
+	{`{% comment %}{% endif %}{% else %}{{ syntax error }}{% endcomment %}`},
+	{`{% comment %}{% comment %}{% if true %}{% endcomment %}{% endfor %}{% endcomment %}`},
 	{`{% raw %}{% if true %}{% endraw %}`},
 }
 
@@ -59,6 +66,41 @@ func TestParseErrors(t *testing.T) {
 	}
 }
 
+func TestParseErrors_location(t *testing.T) {
+	cfg := Config{Grammar: grammarFake{}}
+	_, err := cfg.Parse("text\n  {% if test %}", SourceLoc{Pathname: "page.html", LineNo: 1})
+	require.Error(t, err)
+	require.Equal(t, `page.html:2:3: unterminated "if" block`, err.Error())
+	require.Equal(t, 2, err.LineNumber())
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, "if", pe.Tag)
+	require.Equal(t, 3, pe.ColNo)
+
+	_, err = cfg.Parse("text\n  {% if test %}", SourceLoc{LineNo: 1})
+	require.Error(t, err)
+	require.Equal(t, `Liquid error (line 2, column 3): unterminated "if" block in {% if test %}`, err.Error())
+
+	_, err = cfg.Parse("{% if test %}", SourceLoc{})
+	require.Error(t, err)
+	require.Equal(t, `Liquid error: unterminated "if" block in {% if test %}`, err.Error())
+}
+
+func TestParse_MaxTemplateSize(t *testing.T) {
+	cfg := Config{Grammar: grammarFake{}, MaxTemplateSize: 24}
+	_, err := cfg.Parse(`{% if test %}{% endif %}`, SourceLoc{})
+	require.NoError(t, err)
+
+	_, err = cfg.Parse(`{% if test %}{% endif %} `, SourceLoc{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "template source is 25 bytes, which exceeds the maximum of 24 bytes")
+
+	// the size is checked before the source is parsed
+	_, err = cfg.Parse(`{% if test %}{% if test %}`, SourceLoc{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum")
+}
+
 func TestParser(t *testing.T) {
 	cfg := Config{Grammar: grammarFake{}}
 	for i, test := range parserTests {
@@ -68,3 +110,18 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_InnerSource(t *testing.T) {
+	cfg := Config{Grammar: grammarFake{}}
+	ast, err := cfg.Parse(`a{% for x in xs %}b{{ x }}{% if x %}c{% else %}d{% endif %}{% endfor %}e`, SourceLoc{})
+	require.NoError(t, err)
+	seq, ok := ast.(*ASTSeq)
+	require.True(t, ok)
+	block, ok := seq.Children[1].(*ASTBlock)
+	require.True(t, ok)
+	require.Equal(t, `b{{ x }}{% if x %}c{% else %}d{% endif %}`, block.InnerSource)
+	inner, ok := block.Body[2].(*ASTBlock)
+	require.True(t, ok)
+	require.Equal(t, `c{% else %}d`, inner.InnerSource)
+	require.Equal(t, "", inner.Clauses[0].InnerSource)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 // Scan breaks a string into a sequence of Tokens.
@@ -17,12 +18,15 @@ func Scan(data string, loc SourceLoc, delims []string) (tokens []Token) {
 
 	// TODO error on unterminated {{ and {%
 	// TODO probably an error when a tag contains a {{ or {%, at least outside of a string
+	if loc.ColNo == 0 {
+		loc.ColNo = 1
+	}
 	p, pe := 0, len(data)
 	for _, m := range tokenMatcher.FindAllStringSubmatchIndex(data, -1) {
 		ts, te := m[0], m[1]
 		if p < ts {
 			tokens = append(tokens, Token{Type: TextTokenType, SourceLoc: loc, Source: data[p:ts]})
-			loc.LineNo += strings.Count(data[p:ts], "\n")
+			loc = loc.advance(data[p:ts])
 		}
 		source := data[ts:te]
 		switch {
@@ -50,7 +54,7 @@ func Scan(data string, loc SourceLoc, delims []string) (tokens []Token) {
 			}
 			tokens = append(tokens, tok)
 		}
-		loc.LineNo += strings.Count(source, "\n")
+		loc = loc.advance(source)
 		p = te
 	}
 	if p < pe {
@@ -59,6 +63,17 @@ func Scan(data string, loc SourceLoc, delims []string) (tokens []Token) {
 	return tokens
 }
 
+// advance returns the location that follows the text s, if s begins at loc.
+func (loc SourceLoc) advance(s string) SourceLoc {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		loc.LineNo += strings.Count(s, "\n")
+		loc.ColNo = utf8.RuneCountInString(s[i+1:]) + 1
+	} else {
+		loc.ColNo += utf8.RuneCountInString(s)
+	}
+	return loc
+}
+
 func formTokenMatcher(delims []string) *regexp.Regexp {
 	// On ending a tag we need to exclude anything that appears to be ending a tag that's nested
 	// inside the tag. We form the exclusion expression here.
@@ -74,7 +89,7 @@ func formTokenMatcher(delims []string) *regexp.Regexp {
 	}
 
 	tokenMatcher := regexp.MustCompile(
-		fmt.Sprintf(`%s-?\s*(.+?)\s*-?%s|%s-?\s*(\w+)(?:\s+((?:%v)+?))?\s*-?%s`,
+		fmt.Sprintf(`%s-?\s*((?s:.+?))\s*-?%s|%s-?\s*(\w+)(?:\s+((?:%v)+?))??\s*-?%s`,
 			// QuoteMeta will escape any of these that are regex commands
 			regexp.QuoteMeta(delims[0]), regexp.QuoteMeta(delims[1]),
 			regexp.QuoteMeta(delims[2]), strings.Join(exclusion, "|"), regexp.QuoteMeta(delims[3]),
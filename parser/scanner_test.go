@@ -97,6 +97,52 @@ func TestScan_ws(t *testing.T) {
 			require.Equalf(t, test.right, tok.TrimRight, test.in)
 		})
 	}
+
+	// the whitespace control character isn't an argument
+	for _, src := range []string{`{% tag -%}`, `{%- tag -%}`, `{%-tag-%}`} {
+		tokens := scan(src)
+		require.Len(t, tokens, 1)
+		require.Equalf(t, "tag", tokens[0].Name, src)
+		require.Equalf(t, "", tokens[0].Args, src)
+		require.Truef(t, tokens[0].TrimRight, src)
+	}
+}
+
+func TestScan_sourceLoc(t *testing.T) {
+	tokens := Scan("ab{{ x }}\n  {% tag %}é{{ y }}\n\n{{ z }}", SourceLoc{Pathname: "f.html", LineNo: 1}, nil)
+	require.Len(t, tokens, 8)
+	locs := make([]SourceLoc, len(tokens))
+	for i, tok := range tokens {
+		locs[i] = tok.SourceLoc
+	}
+	require.Equal(t, []SourceLoc{
+		{"f.html", 1, 1},  // ab
+		{"f.html", 1, 3},  // {{ x }}
+		{"f.html", 1, 10}, // newline and indentation
+		{"f.html", 2, 3},  // {% tag %}
+		{"f.html", 2, 12}, // é
+		{"f.html", 2, 13}, // {{ y }}
+		{"f.html", 2, 20}, // newlines
+		{"f.html", 4, 1},  // {{ z }}
+	}, locs)
+
+	tokens = Scan("{{ x }}\n{{ y }}", SourceLoc{Pathname: "f.html", LineNo: 1}, nil)
+	require.Equal(t, SourceLoc{"f.html", 2, 1}, tokens[2].SourceLoc)
+	require.Equal(t, "f.html:2:1", tokens[2].SourceLoc.String())
+}
+
+func TestScan_multiline(t *testing.T) {
+	tokens := Scan("a\n{{ x\n  | f: 1,\n  2 }}\n{%\n  if x and\n  y\n-%}{{ z }}", SourceLoc{Pathname: "f.html", LineNo: 1}, nil)
+	require.Len(t, tokens, 5)
+	require.Equal(t, ObjTokenType, tokens[1].Type)
+	require.Equal(t, "x\n  | f: 1,\n  2", tokens[1].Args)
+	require.Equal(t, SourceLoc{"f.html", 2, 1}, tokens[1].SourceLoc)
+	require.Equal(t, TagTokenType, tokens[3].Type)
+	require.Equal(t, "if", tokens[3].Name)
+	require.Equal(t, "x and\n  y", tokens[3].Args)
+	require.True(t, tokens[3].TrimRight)
+	require.Equal(t, SourceLoc{"f.html", 5, 1}, tokens[3].SourceLoc)
+	require.Equal(t, SourceLoc{"f.html", 8, 4}, tokens[4].SourceLoc)
 }
 
 var scannerCountTestsDelims = []struct {
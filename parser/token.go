@@ -28,10 +28,11 @@ const (
 
 // SourceLoc contains a Token's source location. Pathname is in the local file
 // system; for example "dir/file.html" on Linux and macOS; "dir\file.html" on
-// Windows.
+// Windows. LineNo and ColNo are one-based; zero stands for unknown.
 type SourceLoc struct {
 	Pathname string
 	LineNo   int
+	ColNo    int
 }
 
 // SourceLocation returns the token's source location, for use in error reporting.
@@ -40,6 +41,14 @@ func (c Token) SourceLocation() SourceLoc { return c.SourceLoc }
 // SourceText returns the token's source text, for use in error reporting.
 func (c Token) SourceText() string { return c.Source }
 
+// tagName returns the name of a tag token, for error reporting.
+func (c Token) tagName() string {
+	if c.Type == TagTokenType {
+		return c.Name
+	}
+	return ""
+}
+
 // IsZero returns a boolean indicating whether the location doesn't have a set path.
 func (s SourceLoc) IsZero() bool {
 	return s.Pathname == "" && s.LineNo == 0
@@ -60,7 +69,13 @@ func (c Token) String() string {
 
 func (s SourceLoc) String() string {
 	if s.Pathname != "" {
+		if s.ColNo > 0 {
+			return fmt.Sprintf("%s:%d:%d", s.Pathname, s.LineNo, s.ColNo)
+		}
 		return fmt.Sprintf("%s:%d", s.Pathname, s.LineNo)
 	}
+	if s.ColNo > 0 {
+		return fmt.Sprintf("line %d, column %d", s.LineNo, s.ColNo)
+	}
 	return fmt.Sprintf("line %d", s.LineNo)
 }
@@ -52,6 +52,28 @@ func (g grammar) addBlockDef(ct *blockSyntax) {
 	g.blockDefs[ct.name] = ct
 }
 
+// clone returns a copy of g whose tags and blocks can be defined without affecting g.
+func (g grammar) clone() grammar {
+	result := grammar{
+		tags:      make(map[string]TagCompiler, len(g.tags)),
+		blockDefs: make(map[string]*blockSyntax, len(g.blockDefs)),
+	}
+	for k, v := range g.tags {
+		result.tags[k] = v
+	}
+	for k, v := range g.blockDefs {
+		ct := *v
+		if v.parents != nil {
+			ct.parents = make(map[string]bool, len(v.parents))
+			for p := range v.parents {
+				ct.parents[p] = true
+			}
+		}
+		result.blockDefs[k] = &ct
+	}
+	return result
+}
+
 func (g grammar) findBlockDef(name string) (*blockSyntax, bool) {
 	ct, found := g.blockDefs[name]
 	return ct, found
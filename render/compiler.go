@@ -33,9 +33,10 @@ func (c Config) compileNode(n parser.ASTNode) (Node, parser.Error) {
 			return nil, parser.Errorf(n, "undefined tag %q", n.Name)
 		}
 		node := BlockNode{
-			Token:   n.Token,
-			Body:    body,
-			Clauses: branches,
+			Token:       n.Token,
+			Body:        body,
+			Clauses:     branches,
+			InnerSource: n.InnerSource,
 		}
 		if cd.parser != nil {
 			r, err := cd.parser(node)
@@ -46,7 +47,7 @@ func (c Config) compileNode(n parser.ASTNode) (Node, parser.Error) {
 		}
 		return &node, nil
 	case *parser.ASTRaw:
-		return &RawNode{n.Slices, sourcelessNode{}}, nil
+		return &RawNode{n.Slices, n.TrimLeft, n.TrimRight, sourcelessNode{}}, nil
 	case *parser.ASTSeq:
 		children, err := c.compileNodes(n.Children)
 		if err != nil {
@@ -1,6 +1,7 @@
 package render
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"testing"
@@ -36,6 +37,18 @@ func TestCompile_errors(t *testing.T) {
 			_, err := settings.Compile(test.in, parser.SourceLoc{})
 			require.Errorf(t, err, test.in)
 			require.Containsf(t, err.Error(), test.expected, test.in)
+			var pe *parser.ParseError
+			require.Truef(t, errors.As(err, &pe), test.in)
 		})
 	}
 }
+
+func TestCompile_errorTag(t *testing.T) {
+	settings := NewConfig()
+	addCompilerTestTags(settings)
+	_, err := settings.Compile(`{% block %}{% error_block %}{% enderror_block %}{% endblock %}`, parser.SourceLoc{})
+	var pe *parser.ParseError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, "error_block", pe.Tag)
+	require.Equal(t, "block compiler error", pe.Message)
+}
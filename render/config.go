@@ -1,6 +1,9 @@
 package render
 
 import (
+	"reflect"
+	"time"
+
 	"github.com/osteele/liquid/parser"
 )
 
@@ -8,8 +11,44 @@ import (
 type Config struct {
 	parser.Config
 	grammar
-	Cache           map[string][]byte
-	StrictVariables bool
+	Cache map[string][]byte
+
+	// IncludeCache, if non-nil, caches the compiled templates that are rendered by {% include %}.
+	// NewConfig creates a Config with an empty cache. Set this to nil to disable caching.
+	IncludeCache *IncludeCache
+
+	// SortStructFields causes {% for %} to iterate over the fields of a struct in
+	// alphabetical order. By default, fields are iterated in declaration order.
+	SortStructFields bool
+
+	// SnapshotBindings causes Render to make a deep copy of the maps, slices, and arrays in
+	// the bindings, so that a tag can't modify the caller's values. Pointers and structs are
+	// not copied.
+	SnapshotBindings bool
+
+	// fs reads included templates. If nil, they're read from the local file system.
+	fs FileSystem
+
+	// stringers holds the functions that RegisterStringer registers, by type.
+	stringers map[reflect.Type]func(interface{}) string
+
+	// LoopTiming adds forloop.elapsed_ms, the number of milliseconds since the loop started,
+	// to the forloop object of {% for %} and {% tablerow %} loops. Use this for profiling.
+	LoopTiming bool
+
+	// Clock, if non-nil, returns the current time for LoopTiming. By default this is time.Now.
+	Clock func() time.Time
+
+	// MaxVariableSize, if positive, is the maximum length in bytes of a string that
+	// {% assign %} or {% capture %} can store in a variable.
+	MaxVariableSize int
+
+	// MaxIterations, if positive, is the maximum number of loop iterations in a rendering,
+	// counted across all its loops, including those in included templates.
+	MaxIterations int
+
+	// MaxOutputSize, if positive, is the maximum number of bytes that a rendering can write.
+	MaxOutputSize int
 }
 
 type grammar struct {
@@ -17,11 +56,65 @@ type grammar struct {
 	blockDefs map[string]*blockSyntax
 }
 
+// SetFileSystem sets the FileSystem that included templates are read from.
+// By default, they are read from the local file system.
+func (c *Config) SetFileSystem(fs FileSystem) {
+	c.fs = fs
+}
+
+// RegisterStringer causes {{ object }} to render a value of type t as the string that fn
+// returns, instead of with the default formatting. This also applies to the elements of an
+// array or slice, and to the values that pointers point to.
+//
+// Use this to control the rendering of types, such as those from third-party packages, that
+// can't be given a String or ToLiquid method.
+func (c *Config) RegisterStringer(t reflect.Type, fn func(interface{}) string) {
+	if c.stringers == nil {
+		c.stringers = map[reflect.Type]func(interface{}) string{}
+	}
+	c.stringers[t] = fn
+}
+
+// Clone returns a copy of c that has its own filters, tags, blocks, stringers, globals,
+// settings, translations, and template Cache, so that defining or modifying these in the
+// copy doesn't affect c. If c has an IncludeCache, the copy has a new, empty one. The copy
+// shares c's file system.
+func (c Config) Clone() Config {
+	c.Config = c.Config.Clone()
+	c.grammar = c.grammar.clone()
+	c.Grammar = c.grammar
+	if c.Cache != nil {
+		cache := make(map[string][]byte, len(c.Cache))
+		for k, v := range c.Cache {
+			cache[k] = v
+		}
+		c.Cache = cache
+	}
+	if c.IncludeCache != nil {
+		c.IncludeCache = &IncludeCache{}
+	}
+	if c.stringers != nil {
+		stringers := make(map[reflect.Type]func(interface{}) string, len(c.stringers))
+		for t, fn := range c.stringers {
+			stringers[t] = fn
+		}
+		c.stringers = stringers
+	}
+	return c
+}
+
+func (c Config) fileSystem() FileSystem {
+	if c.fs == nil {
+		return osFileSystem{}
+	}
+	return c.fs
+}
+
 // NewConfig creates a new Settings.
 func NewConfig() Config {
 	g := grammar{
 		tags:      map[string]TagCompiler{},
 		blockDefs: map[string]*blockSyntax{},
 	}
-	return Config{Config: parser.NewConfig(g), grammar: g, Cache: map[string][]byte{}}
+	return Config{Config: parser.NewConfig(g), grammar: g, Cache: map[string][]byte{}, IncludeCache: &IncludeCache{}}
 }
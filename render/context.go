@@ -3,7 +3,6 @@ package render
 import (
 	"bytes"
 	"io"
-	"io/ioutil"
 	"os"
 	"strings"
 
@@ -16,6 +15,13 @@ import (
 type Context interface {
 	// Bindings returns the current lexical environment.
 	Bindings() map[string]interface{}
+	// Config returns the configuration of the current render.
+	// It's used in the implementation of the built-in tags, and is not guaranteed stable.
+	Config() Config
+	// CountIteration records an iteration of a loop, and returns an error if the rendering
+	// has exceeded the configuration's MaxIterations.
+	// It's used in the implementation of the built-in loop tags.
+	CountIteration() error
 	// Get retrieves the value of a variable from the current lexical environment.
 	Get(name string) interface{}
 	// Errorf creates a SourceError, that includes the source location.
@@ -29,6 +35,10 @@ type Context interface {
 	// ExpandTagArg renders the current tag argument string as a Liquid template.
 	// It enables the implementation of tags such as Jekyll's "{% include {{ page.my_variable }} %}" andjekyll-avatar's  "{% avatar {{page.author}} %}".
 	ExpandTagArg() (string, error)
+	// InnerSource is the unparsed source of the current block, between its start and end
+	// tags. Use this to implement a block such as {% highlight %} that processes its source
+	// instead of, or as well as, rendering it.
+	InnerSource() string
 	// InnerString is the rendered content of the current block.
 	// It's used in the implementation of the Liquid "capture" tag and the Jekyll "highlght" tag.
 	InnerString() (string, error)
@@ -98,12 +108,24 @@ func (c rendererContext) WrapError(err error) Error {
 }
 
 func (c rendererContext) Evaluate(expr expressions.Expression) (out interface{}, err error) {
-	return c.ctx.Evaluate(expr)
+	return c.ctx.Evaluate(expr, c.location())
 }
 
 // EvaluateString evaluates an expression within the template context.
 func (c rendererContext) EvaluateString(source string) (out interface{}, err error) {
-	return expressions.EvaluateString(source, expressions.NewContext(c.ctx.bindings, c.ctx.config.Config.Config))
+	return expressions.EvaluateString(source, c.ctx.expressionContext(c.location()))
+}
+
+// location returns the location of the current tag or block.
+func (c rendererContext) location() parser.Locatable {
+	switch {
+	case c.node != nil:
+		return c.node
+	case c.cn != nil:
+		return c.cn
+	default:
+		return invalidLoc
+	}
 }
 
 // Bindings returns the current lexical environment.
@@ -111,9 +133,31 @@ func (c rendererContext) Bindings() map[string]interface{} {
 	return c.ctx.bindings
 }
 
-// Get gets a variable value within an evaluation context.
+// Config returns the render configuration.
+func (c rendererContext) Config() Config {
+	return c.ctx.config
+}
+
+// CountIteration records a loop iteration, and checks it against MaxIterations.
+func (c rendererContext) CountIteration() error {
+	max := c.ctx.config.MaxIterations
+	if max <= 0 {
+		return nil
+	}
+	c.ctx.limits.iterations++
+	if c.ctx.limits.iterations > max {
+		return c.Errorf("loop iterations exceed the limit of %d", max)
+	}
+	return nil
+}
+
+// Get gets a variable value within an evaluation context. A variable that isn't bound
+// is looked up in the configuration's Globals.
 func (c rendererContext) Get(name string) interface{} {
-	return c.ctx.bindings[name]
+	if value, ok := c.ctx.bindings[name]; ok {
+		return value
+	}
+	return c.ctx.config.Globals[name]
 }
 
 func (c rendererContext) ExpandTagArg() (string, error) {
@@ -147,7 +191,7 @@ func (c rendererContext) RenderChildren(w io.Writer) Error {
 }
 
 func (c rendererContext) RenderFile(filename string, b map[string]interface{}) (string, error) {
-	source, err := ioutil.ReadFile(filename)
+	source, err := c.ctx.config.fileSystem().ReadFile(filename)
 	if err != nil && os.IsNotExist(err) {
 		// Is it cached?
 		if cval, ok := c.ctx.config.Cache[filename]; ok {
@@ -158,9 +202,12 @@ func (c rendererContext) RenderFile(filename string, b map[string]interface{}) (
 	} else if err != nil {
 		return "", err
 	}
-	root, err := c.ctx.config.Compile(string(source), c.node.SourceLoc)
+	if c.ctx.includes != nil {
+		c.ctx.includes.add(filename)
+	}
+	root, err := c.compileFile(filename, string(source))
 	if err != nil {
-		return "", err
+		return "", wrapNestedError(err, c.node)
 	}
 	bindings := map[string]interface{}{}
 	for k, v := range c.ctx.bindings {
@@ -170,12 +217,40 @@ func (c rendererContext) RenderFile(filename string, b map[string]interface{}) (
 		bindings[k] = v
 	}
 	buf := new(bytes.Buffer)
-	if err := Render(root, buf, bindings, c.ctx.config); err != nil {
-		return "", err
+	// The bindings are a copy of the current context's, so they don't need to be copied again.
+	if err := renderNode(root, buf, nodeContext{bindings, c.ctx.config, c.ctx.limits, c.ctx.warnings, c.ctx.includes}); err != nil {
+		return "", wrapNestedError(err, c.node)
 	}
 	return buf.String(), nil
 }
 
+// compileFile compiles the source of an included file, using the include cache if there is one.
+// Locations within the compiled template refer to the included file.
+func (c rendererContext) compileFile(filename, source string) (Node, error) {
+	cache := c.ctx.config.IncludeCache
+	if cache != nil {
+		if root, ok := cache.get(filename, source); ok {
+			return root, nil
+		}
+	}
+	root, err := c.ctx.config.Compile(source, parser.SourceLoc{Pathname: filename, LineNo: 1})
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.put(filename, source, root)
+	}
+	return root, nil
+}
+
+// InnerSource returns the source of the current block's body and clauses.
+func (c rendererContext) InnerSource() string {
+	if c.cn == nil {
+		return ""
+	}
+	return c.cn.InnerSource
+}
+
 // InnerString renders the children to a string.
 func (c rendererContext) InnerString() (string, error) {
 	buf := new(bytes.Buffer)
@@ -57,6 +57,13 @@ func addContextTestTags(s Config) {
 			return err
 		}, nil
 	})
+	s.AddTag("test_mutate", func(string) (func(w io.Writer, c Context) error, error) {
+		return func(w io.Writer, c Context) error {
+			c.Get("m").(map[string]interface{})["k"] = "mutated"
+			c.Get("a").([]interface{})[0] = "mutated"
+			return nil
+		}, nil
+	})
 	s.AddBlock("test_block_sourcefile").Compiler(func(c BlockNode) (func(w io.Writer, c Context) error, error) {
 		return func(w io.Writer, c Context) error {
 			_, err := io.WriteString(w, c.SourceFile())
@@ -130,6 +137,127 @@ func TestContext_errors(t *testing.T) {
 	}
 }
 
+type mapFileSystem map[string]string
+
+func (fs mapFileSystem) ReadFile(name string) ([]byte, error) {
+	if s, ok := fs[name]; ok {
+		return []byte(s), nil
+	}
+	return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
+
+func TestContext_SetFileSystem(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetFileSystem(mapFileSystem{"snippets/in_memory.txt": "in memory shadowed={{ shadowed }}"})
+	cfg.Cache["snippets/cached.txt"] = []byte("cached")
+	addContextTestTags(cfg)
+
+	root, err := cfg.Compile(`{% test_render_file snippets/in_memory.txt %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = Render(root, buf, contextTestBindings, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "in memory shadowed=2", buf.String())
+
+	// a file that isn't in the file system falls back to the cache
+	root, err = cfg.Compile(`{% test_render_file snippets/cached.txt %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf = new(bytes.Buffer)
+	err = Render(root, buf, contextTestBindings, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "cached", buf.String())
+
+	// the local file system isn't consulted
+	root, err = cfg.Compile(`{% test_render_file testdata/render_file.txt %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	err = Render(root, ioutil.Discard, contextTestBindings, cfg)
+	require.Error(t, err)
+	require.True(t, os.IsNotExist(err.Cause()))
+}
+
+func TestContext_IncludeCache(t *testing.T) {
+	fs := mapFileSystem{"snippet.txt": "first"}
+	cfg := NewConfig()
+	cfg.SetFileSystem(fs)
+	addContextTestTags(cfg)
+	root, err := cfg.Compile(`{% test_render_file snippet.txt %}{% test_render_file snippet.txt %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+
+	render := func(cfg Config) string {
+		buf := new(bytes.Buffer)
+		err := Render(root, buf, contextTestBindings, cfg)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	require.Equal(t, "firstfirst", render(cfg))
+	require.Len(t, cfg.IncludeCache.entries, 1)
+	var cached Node
+	for _, e := range cfg.IncludeCache.entries {
+		cached = e.root
+	}
+	require.Equal(t, "firstfirst", render(cfg))
+	for _, e := range cfg.IncludeCache.entries {
+		require.True(t, e.root == cached)
+	}
+
+	// a changed source is recompiled
+	fs["snippet.txt"] = "second"
+	require.Equal(t, "secondsecond", render(cfg))
+	require.Len(t, cfg.IncludeCache.entries, 1)
+
+	cfg.IncludeCache.Clear()
+	require.Len(t, cfg.IncludeCache.entries, 0)
+
+	// caching is disabled
+	cfg.IncludeCache = nil
+	require.Equal(t, "secondsecond", render(cfg))
+	fs["snippet.txt"] = "third"
+	require.Equal(t, "thirdthird", render(cfg))
+}
+
+func TestContext_SnapshotBindings(t *testing.T) {
+	newBindings := func() map[string]interface{} {
+		return map[string]interface{}{
+			"x": 1,
+			"m": map[string]interface{}{"k": "original"},
+			"a": []interface{}{"original"},
+		}
+	}
+	cfg := NewConfig()
+	addContextTestTags(cfg)
+	root, err := cfg.Compile(`{% test_mutate %}{{ m.k }},{{ a[0] }}`, parser.SourceLoc{})
+	require.NoError(t, err)
+
+	// by default, a tag can modify the caller's nested values
+	bindings := newBindings()
+	buf := new(bytes.Buffer)
+	require.NoError(t, Render(root, buf, bindings, cfg))
+	require.Equal(t, "mutated,mutated", buf.String())
+	require.Equal(t, "mutated", bindings["m"].(map[string]interface{})["k"])
+
+	cfg.SnapshotBindings = true
+	bindings = newBindings()
+	buf = new(bytes.Buffer)
+	require.NoError(t, Render(root, buf, bindings, cfg))
+	require.Equal(t, "mutated,mutated", buf.String())
+	require.Equal(t, newBindings(), bindings)
+
+	// a value that contains itself is copied with the same structure
+	m := map[string]interface{}{"k": "original"}
+	m["self"] = m
+	a := []interface{}{"original", nil}
+	a[1] = a
+	bindings = map[string]interface{}{"m": m, "a": a, "alias": m}
+	root, err = cfg.Compile(`{% test_mutate %}{{ m.self.self.k }},{{ a[1][1][0] }},{{ alias.k }}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf = new(bytes.Buffer)
+	require.NoError(t, Render(root, buf, bindings, cfg))
+	require.Equal(t, "mutated,mutated,mutated", buf.String())
+	require.Equal(t, "original", m["k"])
+	require.Equal(t, "original", a[0])
+}
+
 func TestContext_file_not_found_error(t *testing.T) {
 	// Test the cause instead of looking for a string, since the error message is
 	// different between Darwin and Linux ("no such file") and Windows ("The
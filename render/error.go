@@ -1,6 +1,9 @@
 package render
 
 import (
+	"errors"
+
+	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/parser"
 )
 
@@ -12,10 +15,41 @@ type Error interface {
 	Error() string
 }
 
+// A RenderError is an error that occurs while a template is rendered; for example an error
+// returned by a filter or a tag.
+type RenderError struct {
+	parser.LocatedError
+	// Filter is the name of the filter that returned the error, if any.
+	Filter string
+}
+
+func newRenderError(le parser.LocatedError) *RenderError {
+	e := &RenderError{LocatedError: le}
+	var fe expressions.FilterError
+	if errors.As(le.Err, &fe) {
+		e.Filter = fe.FilterName
+	}
+	return e
+}
+
 func renderErrorf(loc parser.Locatable, format string, a ...interface{}) Error {
-	return parser.Errorf(loc, format, a...)
+	return newRenderError(parser.NewLocatedError(loc, nil, format, a...))
 }
 
 func wrapRenderError(err error, loc parser.Locatable) Error {
-	return parser.WrapError(err, loc)
+	return parser.WrapErrorWith(err, loc, func(le parser.LocatedError) parser.Error { return newRenderError(le) })
+}
+
+// wrapNestedError wraps an error that occurred in a nested template, such as an included
+// file, in an error that reports the location of the tag that rendered the nested
+// template. The message retains the location within the nested template.
+func wrapNestedError(err error, loc parser.Locatable) Error {
+	if err == nil {
+		return nil
+	}
+	cause := err
+	if e, ok := err.(Error); ok && e.Cause() != nil {
+		cause = e.Cause()
+	}
+	return newRenderError(parser.NewLocatedError(loc, cause, "%s", err))
 }
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"io/ioutil"
+	"sync"
+)
+
+// A FileSystem reads the source of the templates that are rendered by the {% include %} tag,
+// or by other tags that call Context.RenderFile.
+//
+// ReadFile should return an error for which os.IsNotExist is true if the file doesn't exist,
+// so that the renderer can fall back to Config.Cache.
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// osFileSystem is the default FileSystem. It reads from the local file system.
+type osFileSystem struct{}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) { return ioutil.ReadFile(name) }
+
+// An IncludeCache caches the compiled templates that are rendered by the {% include %} tag,
+// or by other tags that call Context.RenderFile, so that a template that is included
+// repeatedly, for example within a loop, is parsed only once.
+//
+// An entry is keyed by the template's pathname, and is used only if the template source is
+// unchanged since it was compiled.
+//
+// The zero value is ready to use. An IncludeCache is safe for concurrent use.
+type IncludeCache struct {
+	mu      sync.Mutex
+	entries map[string]includeCacheEntry
+}
+
+type includeCacheEntry struct {
+	source string
+	root   Node
+}
+
+// Clear removes all the compiled templates from the cache.
+func (c *IncludeCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *IncludeCache) get(filename, source string) (Node, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[filename]
+	if !ok || e.source != source {
+		return nil, false
+	}
+	return e.root, true
+}
+
+func (c *IncludeCache) put(filename, source string, root Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]includeCacheEntry{}
+	}
+	c.entries[filename] = includeCacheEntry{source, root}
+}
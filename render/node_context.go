@@ -1,7 +1,10 @@
 package render
 
 import (
+	"reflect"
+
 	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/parser"
 )
 
 // nodeContext provides the evaluation context for rendering the AST.
@@ -11,6 +14,15 @@ import (
 type nodeContext struct {
 	bindings map[string]interface{}
 	config   Config
+	limits   *renderLimits
+	warnings *warnings // nil unless the rendering collects warnings
+	includes *includes // nil unless the rendering records includes
+}
+
+// renderLimits counts the resources that a rendering uses, for comparison with the
+// configuration's limits. It's shared with the renderings of included templates.
+type renderLimits struct {
+	iterations int
 }
 
 // newNodeContext creates a new evaluation context.
@@ -18,13 +30,117 @@ func newNodeContext(scope map[string]interface{}, c Config) nodeContext {
 	// The assign tag modifies the scope, so make a copy first.
 	// TODO this isn't really the right place for this.
 	vars := map[string]interface{}{}
+	copies := map[copyKey]reflect.Value{}
 	for k, v := range scope {
+		if c.SnapshotBindings && v != nil {
+			v = deepCopy(reflect.ValueOf(v), copies).Interface()
+		}
 		vars[k] = v
 	}
-	return nodeContext{vars, c}
+	return nodeContext{vars, c, &renderLimits{}, nil, nil}
+}
+
+// warnings collects the warnings of a rendering, without duplicates.
+// It's shared with the renderings of included templates.
+type warnings struct {
+	list []Error
+	seen map[string]bool
+}
+
+func (ws *warnings) add(err error, loc parser.Locatable) {
+	e := wrapRenderError(err, loc)
+	if !ws.seen[e.Error()] {
+		ws.seen[e.Error()] = true
+		ws.list = append(ws.list, e)
+	}
+}
+
+// includes records the pathnames of the templates that a rendering includes, in the
+// order in which they are first rendered. It's shared with the renderings of included templates.
+type includes struct {
+	names []string
+	seen  map[string]bool
+}
+
+func (is *includes) add(name string) {
+	if !is.seen[name] {
+		is.seen[name] = true
+		is.names = append(is.names, name)
+	}
+}
+
+// A copyKey identifies a map or slice that deepCopy has copied.
+type copyKey struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
 }
 
-// Evaluate evaluates an expression within the template context.
-func (c nodeContext) Evaluate(expr expressions.Expression) (out interface{}, err error) {
-	return expr.Evaluate(expressions.NewContext(c.bindings, c.config.Config.Config))
+// deepCopy returns a copy of v, in which maps, slices, and arrays are copied recursively.
+// Other values, including pointers and the values of struct fields, are shared with v.
+//
+// copies holds the maps and slices that have already been copied, so that a value that
+// contains itself, or that occurs more than once, is copied once.
+func deepCopy(v reflect.Value, copies map[copyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), copies))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{v.Type(), v.Pointer(), 0}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[key] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), copies))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{v.Type(), v.Pointer(), v.Len()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copies[key] = c
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// Evaluate evaluates an expression within the template context. If the rendering
+// collects warnings, these are reported at loc.
+func (c nodeContext) Evaluate(expr expressions.Expression, loc parser.Locatable) (out interface{}, err error) {
+	return expr.Evaluate(c.expressionContext(loc))
+}
+
+// expressionContext returns an expression context for the template context.
+func (c nodeContext) expressionContext(loc parser.Locatable) expressions.Context {
+	cfg := c.config.Config.Config
+	if ws := c.warnings; ws != nil {
+		cfg.Warn = func(err error) { ws.add(err, loc) }
+	}
+	return expressions.NewContext(c.bindings, cfg)
 }
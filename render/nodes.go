@@ -20,11 +20,15 @@ type BlockNode struct {
 	renderer func(io.Writer, Context) error
 	Body     []Node
 	Clauses  []*BlockNode
+	// InnerSource is the unparsed source between the start and end tags of a block,
+	// including its clauses. It's empty for a clause.
+	InnerSource string
 }
 
 // RawNode holds the text between the start and end of a raw tag.
 type RawNode struct {
-	slices []string
+	slices              []string
+	trimLeft, trimRight bool
 	sourcelessNode
 }
 
@@ -12,15 +12,71 @@ import (
 )
 
 // Render renders the render tree.
+//
+// Each call creates a new context for the state of the rendering. A tag renderer must keep
+// its state in the context, and not in the closure that its compiler returns, so that the
+// same tree can be rendered concurrently.
+//
+// If the configuration sets MaxOutputSize, writing more than this returns an error.
 func Render(node Node, w io.Writer, vars map[string]interface{}, c Config) Error {
+	if c.MaxOutputSize > 0 {
+		w = &limitWriter{w: w, max: c.MaxOutputSize}
+	}
+	return renderNode(node, w, newNodeContext(vars, c))
+}
+
+// RenderWithWarnings renders the node as Render does, and also returns the warnings
+// that the rendering encountered: references to undefined variables and filters that,
+// since c doesn't set StrictVariables or StrictFilters, don't fail the rendering.
+// Each warning records the location of the reference, and is reported once.
+func RenderWithWarnings(node Node, w io.Writer, vars map[string]interface{}, c Config) ([]Error, Error) {
+	if c.MaxOutputSize > 0 {
+		w = &limitWriter{w: w, max: c.MaxOutputSize}
+	}
+	ctx := newNodeContext(vars, c)
+	ctx.warnings = &warnings{seen: map[string]bool{}}
+	err := renderNode(node, w, ctx)
+	return ctx.warnings.list, err
+}
+
+// RenderWithIncludes renders the node as Render does, and also returns the pathnames of the
+// templates that the rendering included, by {% include %} or by other tags that call
+// Context.RenderFile, in the order in which they were first rendered and without duplicates.
+//
+// Unlike a static analysis of the template source, this reflects the templates that were
+// actually rendered; for example, it omits an include inside an {% if %} whose condition
+// is false.
+func RenderWithIncludes(node Node, w io.Writer, vars map[string]interface{}, c Config) ([]string, Error) {
+	if c.MaxOutputSize > 0 {
+		w = &limitWriter{w: w, max: c.MaxOutputSize}
+	}
+	ctx := newNodeContext(vars, c)
+	ctx.includes = &includes{seen: map[string]bool{}}
+	err := renderNode(node, w, ctx)
+	return ctx.includes.names, err
+}
+
+func renderNode(node Node, w io.Writer, ctx nodeContext) Error {
 	tw := trimWriter{w: w}
-	if err := node.render(&tw, newNodeContext(vars, c)); err != nil {
+	if err := node.render(&tw, ctx); err != nil {
 		return err
 	}
-	if err := tw.Flush(); err != nil {
-		panic(err)
+	return wrapRenderError(tw.Flush(), invalidLoc)
+}
+
+// A limitWriter writes to w, and returns an error instead of writing more than max bytes in all.
+type limitWriter struct {
+	w      io.Writer
+	n, max int
+}
+
+func (lw *limitWriter) Write(b []byte) (int, error) {
+	if lw.n+len(b) > lw.max {
+		return 0, fmt.Errorf("output exceeds the limit of %d bytes", lw.max)
 	}
-	return nil
+	n, err := lw.w.Write(b)
+	lw.n += n
+	return n, err
 }
 
 // RenderASTSequence renders a sequence of nodes.
@@ -31,10 +87,7 @@ func (c nodeContext) RenderSequence(w io.Writer, seq []Node) Error {
 			return err
 		}
 	}
-	if err := tw.Flush(); err != nil {
-		panic(err)
-	}
-	return nil
+	return wrapRenderError(tw.Flush(), invalidLoc)
 }
 
 func (n *BlockNode) render(w *trimWriter, ctx nodeContext) Error {
@@ -54,25 +107,27 @@ func (n *BlockNode) render(w *trimWriter, ctx nodeContext) Error {
 }
 
 func (n *RawNode) render(w *trimWriter, ctx nodeContext) Error {
+	w.TrimLeft(n.trimLeft)
 	for _, s := range n.slices {
 		_, err := io.WriteString(w, s)
 		if err != nil {
 			return wrapRenderError(err, n)
 		}
 	}
+	w.TrimRight(n.trimRight)
 	return nil
 }
 
 func (n *ObjectNode) render(w *trimWriter, ctx nodeContext) Error {
 	w.TrimLeft(n.TrimLeft)
-	value, err := ctx.Evaluate(n.expr)
+	value, err := ctx.Evaluate(n.expr, n)
 	if err != nil {
 		return wrapRenderError(err, n)
 	}
 	if value == nil && ctx.config.StrictVariables {
 		return wrapRenderError(errors.New("undefined variable"), n)
 	}
-	if err := wrapRenderError(writeObject(w, value), n); err != nil {
+	if err := wrapRenderError(writeObject(w, value, ctx.config.stringers), n); err != nil {
 		return err
 	}
 	w.TrimRight(n.TrimRight)
@@ -100,19 +155,33 @@ func (n *TextNode) render(w *trimWriter, ctx nodeContext) Error {
 	return wrapRenderError(err, n)
 }
 
-// writeObject writes a value used in an object node
-func writeObject(w io.Writer, value interface{}) error {
+// writeObject writes a value used in an object node. A value whose type is a key
+// of stringers is written as the string that the corresponding function returns.
+func writeObject(w io.Writer, value interface{}, stringers map[reflect.Type]func(interface{}) string) error {
+	if fn, ok := stringers[reflect.TypeOf(value)]; ok && value != nil {
+		_, err := io.WriteString(w, fn(value))
+		return err
+	}
 	value = values.ToLiquid(value)
 	if value == nil {
 		return nil
 	}
 	switch value := value.(type) {
+	case string:
+		_, err := io.WriteString(w, value)
+		return err
 	case time.Time:
 		_, err := io.WriteString(w, value.Format("2006-01-02 15:04:05 -0700"))
 		return err
 	case []byte:
 		_, err := w.Write(value)
 		return err
+	case float32:
+		_, err := io.WriteString(w, values.FormatFloat(float64(value), 32))
+		return err
+	case float64:
+		_, err := io.WriteString(w, values.FormatFloat(value, 64))
+		return err
 		// there used be a case on fmt.Stringer here, but fmt.Sprint produces better results than obj.Write
 		// for instances of error and *string
 	}
@@ -122,14 +191,17 @@ func writeObject(w io.Writer, value interface{}) error {
 		for i := 0; i < rt.Len(); i++ {
 			item := rt.Index(i)
 			if item.IsValid() {
-				if err := writeObject(w, item.Interface()); err != nil {
+				if err := writeObject(w, item.Interface(), stringers); err != nil {
 					return err
 				}
 			}
 		}
 		return nil
 	case reflect.Ptr:
-		return writeObject(w, reflect.ValueOf(value).Elem())
+		if rt.IsNil() {
+			return nil
+		}
+		return writeObject(w, rt.Elem().Interface(), stringers)
 	default:
 		_, err := io.WriteString(w, fmt.Sprint(value))
 		return err
@@ -2,12 +2,15 @@ package render
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/parser"
 	"github.com/stretchr/testify/require"
 )
@@ -19,6 +22,10 @@ var renderTests = []struct{ in, out string }{
 	{`{{ false }}`, "false"},
 	{`{{ 12 }}`, "12"},
 	{`{{ 12.3 }}`, "12.3"},
+	{`{{ 1 }}`, "1"},
+	{`{{ 1.0 }}`, "1.0"},
+	{`{{ 1.5 }}`, "1.5"},
+	{`{{ 1.50 }}`, "1.5"},
 	{`{{ date }}`, "2015-07-17 15:04:05 +0000"},
 	{`{{ "string" }}`, "string"},
 	{`{{ array }}`, "firstsecondthird"},
@@ -58,6 +65,10 @@ var renderStrictTests = []struct{ in, out string }{
 	{`{{ false }}`, "false"},
 	{`{{ 12 }}`, "12"},
 	{`{{ 12.3 }}`, "12.3"},
+	{`{{ 1 }}`, "1"},
+	{`{{ 1.0 }}`, "1.0"},
+	{`{{ 1.5 }}`, "1.5"},
+	{`{{ 1.50 }}`, "1.5"},
 	{`{{ date }}`, "2015-07-17 15:04:05 +0000"},
 	{`{{ "string" }}`, "string"},
 	{`{{ array }}`, "firstsecondthird"},
@@ -124,10 +135,129 @@ func TestRenderErrors(t *testing.T) {
 			err = Render(root, ioutil.Discard, renderTestBindings, cfg)
 			require.Errorf(t, err, test.in)
 			require.Containsf(t, err.Error(), test.out, test.in)
+			var re *RenderError
+			require.Truef(t, errors.As(err, &re), test.in)
 		})
 	}
 }
 
+type stringerTestPoint struct{ X, Y int }
+
+func TestRender_RegisterStringer(t *testing.T) {
+	cfg := NewConfig()
+	cfg.RegisterStringer(reflect.TypeOf(stringerTestPoint{}), func(v interface{}) string {
+		p := v.(stringerTestPoint)
+		return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+	})
+	cfg.RegisterStringer(reflect.TypeOf(time.Time{}), func(v interface{}) string {
+		return v.(time.Time).Format("2006-01-02")
+	})
+	bindings := map[string]interface{}{
+		"point":  stringerTestPoint{1, 2},
+		"ptr":    &stringerTestPoint{3, 4},
+		"points": []stringerTestPoint{{1, 2}, {3, 4}},
+		"date":   time.Date(2015, 7, 17, 15, 4, 5, 0, time.UTC),
+		"nilptr": (*stringerTestPoint)(nil),
+	}
+	tests := []struct{ in, out string }{
+		{`{{ point }}`, "(1, 2)"},
+		{`{{ ptr }}`, "(3, 4)"},
+		{`{{ points }}`, "(1, 2)(3, 4)"},
+		{`{{ date }}`, "2015-07-17"},
+		{`{{ nilptr }}`, ""},
+		{`{{ point.X }}`, "1"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			root, err := cfg.Compile(test.in, parser.SourceLoc{})
+			require.NoErrorf(t, err, test.in)
+			buf := new(bytes.Buffer)
+			err = Render(root, buf, bindings, cfg)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.out, buf.String(), test.in)
+		})
+	}
+
+	// without a registered stringer, the default formatting is used
+	root, err := NewConfig().Compile(`{{ point }} {{ date }}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, Render(root, buf, bindings, NewConfig()))
+	require.Equal(t, "{1 2} 2015-07-17 15:04:05 +0000", buf.String())
+}
+
+func TestRenderErrors_filter(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFilter("fail", func(interface{}) (interface{}, error) { return nil, fmt.Errorf("filter failed") })
+	root, err := cfg.Compile("\n {{ 1 | fail }}", parser.SourceLoc{LineNo: 1})
+	require.NoError(t, err)
+	err = Render(root, ioutil.Discard, renderTestBindings, cfg)
+	require.Error(t, err)
+	require.Equal(t, `Liquid error (line 2, column 2): error applying filter "fail" ("filter failed") in {{ 1 | fail }}`, err.Error())
+	var re *RenderError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, "fail", re.Filter)
+	require.Equal(t, 2, re.LineNo)
+	require.Equal(t, 2, re.ColNo)
+	var fe expressions.FilterError
+	require.True(t, errors.As(err, &fe))
+	var pe *parser.ParseError
+	require.False(t, errors.As(err, &pe))
+}
+
+func TestRender_MaxOutputSize(t *testing.T) {
+	cfg := NewConfig()
+	cfg.MaxOutputSize = 10
+	for _, test := range []struct{ in, out string }{
+		{`0123456789`, "0123456789"},
+		{`{{ "abc" }}{{ "defghij" }}`, "abcdefghij"},
+		{`01234567 {{- "89" }}  `, ""},
+		{`0123456789{{ "a" }}`, ""},
+		{`01234567{{ "89abc" }}`, ""},
+	} {
+		root, err := cfg.Compile(test.in, parser.SourceLoc{})
+		require.NoErrorf(t, err, test.in)
+		buf := new(bytes.Buffer)
+		err = Render(root, buf, renderTestBindings, cfg)
+		if test.out == "" {
+			require.Errorf(t, err, test.in)
+			require.Containsf(t, err.Error(), "output exceeds the limit of 10 bytes", test.in)
+			continue
+		}
+		require.NoErrorf(t, err, test.in)
+		require.Equalf(t, test.out, buf.String(), test.in)
+	}
+}
+
+func TestRenderWithWarnings(t *testing.T) {
+	cfg := NewConfig()
+	cfg.StrictFilters = false
+	src := "line 1\n{{ missing }}{{ x | undefined_filter }}\n{{ x }}{{ missing.a }}"
+	root, err := cfg.Compile(src, parser.SourceLoc{Pathname: "page.html", LineNo: 1})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	warnings, err := RenderWithWarnings(root, buf, map[string]interface{}{"x": 1}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "line 1\n1\n1", buf.String())
+	require.Len(t, warnings, 3)
+	require.Contains(t, warnings[0].Error(), `undefined variable "missing"`)
+	require.Equal(t, "page.html", warnings[0].Path())
+	require.Equal(t, 2, warnings[0].LineNumber())
+	require.Contains(t, warnings[1].Error(), `undefined filter "undefined_filter"`)
+	require.Equal(t, 2, warnings[1].LineNumber())
+	require.Contains(t, warnings[2].Error(), `undefined variable "missing"`)
+	require.Equal(t, 3, warnings[2].LineNumber())
+
+	warnings, err = RenderWithWarnings(root, io.Discard, map[string]interface{}{"x": 1, "missing": nil}, cfg)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+
+	cfg.StrictVariables = true
+	_, err = RenderWithWarnings(root, io.Discard, map[string]interface{}{"x": 1}, cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined variable")
+}
+
 func TestRenderStrictVariables(t *testing.T) {
 	cfg := NewConfig()
 	cfg.StrictVariables = true
@@ -148,6 +278,38 @@ func TestRenderStrictVariables(t *testing.T) {
 	}
 }
 
+var strictVariableErrorTests = []struct{ in, out string }{
+	{`{{ missing }}`, `undefined variable "missing"`},
+	{`{{ missing.title }}`, `undefined variable "missing"`},
+	{`{{ array[missing] }}`, `undefined variable "missing"`},
+}
+
+func TestRenderStrictVariables_errors(t *testing.T) {
+	cfg := NewConfig()
+	addRenderTestTags(cfg)
+	loc := parser.SourceLoc{Pathname: "strict.html", LineNo: 3}
+	for i, test := range strictVariableErrorTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			root, err := cfg.Compile(test.in, loc)
+			require.NoErrorf(t, err, test.in)
+
+			// lenient by default
+			buf := new(bytes.Buffer)
+			err = Render(root, buf, renderTestBindings, cfg)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, "", buf.String(), test.in)
+
+			strict := cfg
+			strict.StrictVariables = true
+			err = Render(root, ioutil.Discard, renderTestBindings, strict)
+			require.Errorf(t, err, test.in)
+			require.Containsf(t, err.Error(), test.out, test.in)
+			require.Equalf(t, "strict.html", err.Path(), test.in)
+			require.Equalf(t, 3, err.LineNumber(), test.in)
+		})
+	}
+}
+
 func addRenderTestTags(cfg Config) {
 	cfg.AddTag("y", func(string) (func(io.Writer, Context) error, error) {
 		return func(w io.Writer, _ Context) error {
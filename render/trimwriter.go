@@ -13,6 +13,7 @@ type trimWriter struct {
 	w         io.Writer
 	buf       bytes.Buffer
 	trimRight bool
+	err       error // an error from flushing the buffer in TrimLeft
 }
 
 // This violates the letter of the protocol by returning the count of the
@@ -22,6 +23,9 @@ type trimWriter struct {
 // truthfully here would cause some callers to return io.ErrShortWrite, ruining
 // this as an io.Writer.
 func (tw *trimWriter) Write(b []byte) (int, error) {
+	if tw.err != nil {
+		return 0, tw.err
+	}
 	n := len(b)
 	if tw.trimRight {
 		b = bytes.TrimLeftFunc(b, unicode.IsSpace)
@@ -43,6 +47,9 @@ func (tw *trimWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 func (tw *trimWriter) Flush() (err error) {
+	if tw.err != nil {
+		return tw.err
+	}
 	if tw.buf.Len() > 0 {
 		_, err = tw.buf.WriteTo(tw.w)
 		tw.buf.Reset()
@@ -52,9 +59,8 @@ func (tw *trimWriter) Flush() (err error) {
 
 func (tw *trimWriter) TrimLeft(f bool) {
 	if !f && tw.buf.Len() > 0 {
-		if err := tw.Flush(); err != nil {
-			panic(err)
-		}
+		// TrimLeft can't return an error, so the next Write or Flush returns it
+		tw.err = tw.Flush()
 	}
 	tw.buf.Reset()
 	tw.trimRight = false
@@ -24,6 +24,13 @@ var cfTagTests = []struct{ in, expected string }{
 	{`{% case 1 %}{% when 1,2 %}a{% else %}b{% endcase %}`, "a"},
 	{`{% case 2 %}{% when 1,2 %}a{% else %}b{% endcase %}`, "a"},
 	{`{% case 3 %}{% when 1,2 %}a{% else %}b{% endcase %}`, "b"},
+	{`{% case "b" %}{% when "a", "b" %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case 2 %}{% when 1 or 2 %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case 3 %}{% when 1 or 2 %}a{% else %}b{% endcase %}`, "b"},
+	{`{% case "c" %}{% when "a" or "b", "c" %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case x %}{% when 1, obj.a or 123 %}a{% else %}b{% endcase %}`, "a"},
+	{`{% case "z" %}{% when "a", "b" %}a{% when "c" or "d" %}c{% else %}else{% endcase %}`, "else"},
+	{`{% case "z" %}{% when "a", "b" %}a{% when "c" or "d" %}c{% endcase %}`, ""},
 
 	// if
 	{`{% if true %}true{% endif %}`, "true"},
@@ -39,6 +46,40 @@ var cfTagTests = []struct{ in, expected string }{
 	{`{% if true %}0{% elsif true %}1{% else %}2{% endif %}`, "0"},
 	{`{% if false %}0{% elsif true %}1{% else %}2{% endif %}`, "1"},
 	{`{% if false %}0{% elsif false %}1{% else %}2{% endif %}`, "2"},
+	{`{% if x == 0 %}0{% elsif x == 1 %}1{% elsif x == 123 %}2{% elsif x > 100 %}3{% else %}4{% endif %}`, "2"},
+	{`{% if x == 0 %}0{% elsif x == 1 %}1{% elsif x == 2 %}2{% elsif x < 100 %}3{% else %}4{% endif %}`, "4"},
+	{`{% if x == 0 %}0{% elsif x == 1 %}1{% elsif x == 2 %}2{% elsif x < 100 %}3{% endif %}`, ""},
+	{`{% if false %}0{% elsif obj.a == 1 and x %}1{% elsif true %}2{% endif %}`, "1"},
+
+	// unless
+	{`{% unless true %}0{% else %}1{% endunless %}`, "1"},
+	{`{% unless x %}0{% elsif y %}1{% elsif x > 100 %}2{% else %}3{% endunless %}`, "2"},
+	{`{% unless x %}0{% elsif y %}1{% else %}2{% endunless %}`, "2"},
+
+	// empty
+	{`{% if y == empty %}empty{% else %}not empty{% endif %}`, "not empty"},
+	{`{% if empty_string == empty %}empty{% endif %}`, "empty"},
+	{`{% if empty_array == empty %}empty{% endif %}`, "empty"},
+	{`{% if empty_map == empty %}empty{% endif %}`, "empty"},
+	{`{% if empty == empty_array %}empty{% endif %}`, "empty"},
+	{`{% if animals == empty %}empty{% else %}not empty{% endif %}`, "not empty"},
+	{`{% if obj != empty %}not empty{% endif %}`, "not empty"},
+	{`{% if x != empty %}not empty{% endif %}`, "not empty"},
+	{`{% if y < "a" or y > "a" or y < 1 or y > 1 %}ordered{% else %}unordered{% endif %}`, "unordered"},
+	{`[{{ empty }}]`, "[]"},
+
+	// blank
+	{`{% if y == blank %}blank{% endif %}`, "blank"},
+	{`{% if false == blank %}blank{% endif %}`, "blank"},
+	{`{% if empty_string == blank %}blank{% endif %}`, "blank"},
+	{`{% if "  " == blank %}blank{% endif %}`, "blank"},
+	{`{% if "  " == empty %}empty{% else %}not empty{% endif %}`, "not empty"},
+	{`{% if empty_array == blank %}blank{% endif %}`, "blank"},
+	{`{% if empty_map == blank %}blank{% endif %}`, "blank"},
+	{`{% if page.title == blank %}blank{% else %}not blank{% endif %}`, "not blank"},
+	{`{% if x != blank %}not blank{% endif %}`, "not blank"},
+	{`{% if blank == y %}blank{% endif %}`, "blank"},
+	{`[{{ blank }}]`, "[]"},
 
 	// unless
 	{`{% unless true %}false{% endunless %}`, ""},
@@ -1,18 +1,49 @@
 package tags
 
 import (
+	"fmt"
 	"io"
 	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/render"
 )
 
+// An include is a parse of the arguments to an {% include %} tag:
+//
+//	{% include 'snippet' %}
+//	{% include 'snippet' with object %}
+//	{% include 'snippet' with object as name %}
+//	{% include 'snippet', a: 1, b: x %}
+type include struct {
+	file   expressions.Expression
+	with   expressions.Expression
+	as     string
+	params []includeParam
+}
+
+type includeParam struct {
+	name string
+	expr expressions.Expression
+}
+
+var (
+	includeParamRE = regexp.MustCompile(`^\s*([\w-]+)\s*:(.*)$`)
+	includeAsRE    = regexp.MustCompile(`^[\w-]+$`)
+)
+
 func includeTag(source string) (func(io.Writer, render.Context) error, error) {
+	inc, err := parseInclude(source)
+	if err != nil {
+		return nil, err
+	}
 	return func(w io.Writer, ctx render.Context) error {
 		// It might be more efficient to add a context interface to render bytes
 		// to a writer. The status quo keeps the interface light at the expense of some overhead
 		// here.
-		value, err := ctx.EvaluateString(ctx.TagArgs())
+		value, err := ctx.Evaluate(inc.file)
 		if err != nil {
 			return err
 		}
@@ -20,8 +51,12 @@ func includeTag(source string) (func(io.Writer, render.Context) error, error) {
 		if !ok {
 			return ctx.Errorf("include requires a string argument; got %v", value)
 		}
+		bindings, err := inc.bindings(ctx, rel)
+		if err != nil {
+			return err
+		}
 		filename := filepath.Join(filepath.Dir(ctx.SourceFile()), rel)
-		s, err := ctx.RenderFile(filename, map[string]interface{}{})
+		s, err := ctx.RenderFile(filename, bindings)
 		if err != nil {
 			return err
 		}
@@ -29,3 +64,121 @@ func includeTag(source string) (func(io.Writer, render.Context) error, error) {
 		return err
 	}, nil
 }
+
+// bindings evaluates the with object or the keyword parameters in the caller's context.
+func (inc include) bindings(ctx render.Context, rel string) (map[string]interface{}, error) {
+	bindings := map[string]interface{}{}
+	if inc.with != nil {
+		value, err := ctx.Evaluate(inc.with)
+		if err != nil {
+			return nil, err
+		}
+		name := inc.as
+		if name == "" {
+			// the variable is named after the snippet, without its directory or extension
+			base := filepath.Base(rel)
+			name = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		bindings[name] = value
+	}
+	for _, param := range inc.params {
+		value, err := ctx.Evaluate(param.expr)
+		if err != nil {
+			return nil, err
+		}
+		bindings[param.name] = value
+	}
+	return bindings, nil
+}
+
+func parseInclude(source string) (inc include, err error) {
+	parts := splitTopLevel(source, ",")
+	head, rest := parts[0], parts[1:]
+	if i := indexTopLevelWord(head, "with"); i >= 0 {
+		if len(rest) > 0 {
+			return inc, fmt.Errorf("include can't combine with and keyword parameters")
+		}
+		head, rest := head[:i], head[i+len("with"):]
+		as := ""
+		if j := indexTopLevelWord(rest, "as"); j >= 0 {
+			rest, as = rest[:j], strings.TrimSpace(rest[j+len("as"):])
+			if !includeAsRE.MatchString(as) {
+				return inc, fmt.Errorf("include with ... as requires a variable name; got %q", as)
+			}
+		}
+		if inc.with, err = expressions.Parse(rest); err != nil {
+			return inc, err
+		}
+		inc.as = as
+		inc.file, err = expressions.Parse(head)
+		return inc, err
+	}
+	if inc.file, err = expressions.Parse(head); err != nil {
+		return inc, err
+	}
+	for _, part := range rest {
+		m := includeParamRE.FindStringSubmatch(part)
+		if m == nil {
+			return inc, fmt.Errorf("include parameters must have the form name: value; got %q", strings.TrimSpace(part))
+		}
+		expr, err := expressions.Parse(m[2])
+		if err != nil {
+			return inc, err
+		}
+		inc.params = append(inc.params, includeParam{m[1], expr})
+	}
+	return inc, nil
+}
+
+// splitTopLevel splits s at each occurrence of sep that is outside a string literal
+// or brackets.
+func splitTopLevel(s, sep string) []string {
+	var parts []string
+	for {
+		i := indexTopLevel(s, func(rest string, _ int) bool { return strings.HasPrefix(rest, sep) })
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+}
+
+// indexTopLevelWord returns the index of the first occurrence of word in s that is
+// preceded by whitespace, followed by whitespace or the end of s, and outside a
+// string literal or brackets; or -1.
+func indexTopLevelWord(s, word string) int {
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+	return indexTopLevel(s, func(rest string, i int) bool {
+		return i > 0 && isSpace(s[i-1]) &&
+			strings.HasPrefix(rest, word) &&
+			(len(rest) == len(word) || isSpace(rest[len(word)]))
+	})
+}
+
+// indexTopLevel returns the index of the first byte of s that is outside a string
+// literal or brackets, and at which match returns true; or -1.
+func indexTopLevel(s string, match func(rest string, i int) bool) int {
+	var (
+		quote byte
+		depth int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && match(s[i:], i):
+			return i
+		}
+	}
+	return -1
+}
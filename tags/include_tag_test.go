@@ -2,9 +2,13 @@ package tags
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/osteele/liquid/parser"
@@ -46,6 +50,97 @@ func TestIncludeTag(t *testing.T) {
 	require.Contains(t, err.Error(), "requires a string")
 }
 
+func TestIncludeTag_parameters(t *testing.T) {
+	config := render.NewConfig()
+	loc := parser.SourceLoc{Pathname: "testdata/include_source.html", LineNo: 1}
+	AddStandardTags(config)
+	bindings := map[string]interface{}{
+		"var":     "value",
+		"product": map[string]interface{}{"title": "outer"},
+		"obj":     map[string]interface{}{"title": "Cool Shirt"},
+		"x":       "ex",
+	}
+
+	tests := []struct{ in, expected string }{
+		{`{% include "product.html" with obj %}`, "Cool Shirt"},
+		{`{% include "product.html" %}`, "outer"},
+		{`{% include "product_as.html" with obj as item %}`, "Cool Shirt"},
+		{`{% include "product_as.html" with obj as item %}{{ item.title }}`, "Cool Shirt"},
+		{`{% include "include_params.html", a: 1, b: x %}`, "1,ex,value"},
+		{`{% include "include_params.html", a: "with, as", b: obj.title %}`, "with, as,Cool Shirt,value"},
+		{`{% include "include_params.html", var: "override" %}`, ",,override"},
+		{`{% include "include_params.html" %}{{ a }}`, ",,value"},
+	}
+	for _, test := range tests {
+		root, err := config.Compile(test.in, loc)
+		require.NoError(t, err, test.in)
+		buf := new(bytes.Buffer)
+		err = render.Render(root, buf, bindings, config)
+		require.NoError(t, err, test.in)
+		require.Equal(t, test.expected, strings.TrimSpace(buf.String()), test.in)
+	}
+
+	errorTests := []struct{ in, expected string }{
+		{`{% include "product.html" with obj, a: 1 %}`, "can't combine with and keyword parameters"},
+		{`{% include "product.html" with obj as %}`, "requires a variable name"},
+		{`{% include "product.html" with obj as a b %}`, "requires a variable name"},
+		{`{% include "product.html", 1 %}`, "must have the form name: value"},
+		{`{% include "product.html" with %}`, "syntax error"},
+	}
+	for _, test := range errorTests {
+		_, err := config.Compile(test.in, loc)
+		require.Error(t, err, test.in)
+		require.Contains(t, err.Error(), test.expected, test.in)
+	}
+}
+
+type mapFileSystem map[string]string
+
+func (fs mapFileSystem) ReadFile(name string) ([]byte, error) {
+	if s, ok := fs[filepath.ToSlash(name)]; ok {
+		return []byte(s), nil
+	}
+	return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
+
+func TestIncludeTag_SetFileSystem(t *testing.T) {
+	config := render.NewConfig()
+	config.SetFileSystem(mapFileSystem{"templates/snippet.html": "in memory {{ var }}"})
+	loc := parser.SourceLoc{Pathname: "templates/page.html", LineNo: 1}
+	AddStandardTags(config)
+
+	root, err := config.Compile(`{% include "snippet.html" %}`, loc)
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, includeTestBindings, config)
+	require.NoError(t, err)
+	require.Equal(t, "in memory value", buf.String())
+
+	root, err = config.Compile(`{% include "missing.html" %}`, loc)
+	require.NoError(t, err)
+	err = render.Render(root, ioutil.Discard, includeTestBindings, config)
+	require.Error(t, err)
+	require.True(t, os.IsNotExist(err.Cause()))
+}
+
+func TestIncludeTag_nested_error_location(t *testing.T) {
+	config := render.NewConfig()
+	config.AddFilter("fail", func(string) (string, error) { return "", errors.New("failed") })
+	loc := parser.SourceLoc{Pathname: "testdata/include_source.html", LineNo: 1}
+	AddStandardTags(config)
+
+	root, err := config.Compile("line 1\nline 2\n  {% include \"include_nested.html\" %}", loc)
+	require.NoError(t, err)
+	rerr := render.Render(root, ioutil.Discard, includeTestBindings, config)
+	require.Error(t, rerr)
+	require.Equal(t, "testdata/include_source.html", rerr.Path())
+	require.Equal(t, 3, rerr.LineNumber())
+	nested, inner := filepath.Join("testdata", "include_nested.html"), filepath.Join("testdata", "include_filter_error.html")
+	require.Equal(t,
+		"testdata/include_source.html:3:3: "+nested+":1:1: "+inner+":2:8: error applying filter \"fail\" (\"failed\")",
+		rerr.Error())
+}
+
 func TestIncludeTag_file_not_found_error(t *testing.T) {
 	config := render.NewConfig()
 	loc := parser.SourceLoc{Pathname: "testdata/include_source.html", LineNo: 1}
@@ -74,3 +169,61 @@ func TestIncludeTag_cached_value_handling(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "include-content", strings.TrimSpace(buf.String()))
 }
+
+func TestIncludeTag_RenderWithIncludes(t *testing.T) {
+	config := render.NewConfig()
+	loc := parser.SourceLoc{Pathname: "testdata/include_source.html", LineNo: 1}
+	AddStandardTags(config)
+
+	root, err := config.Compile(`{% include "include_target_2.html" %}{% if test %}{% include "include_target.html" %}{% endif %}{% include "include_target_2.html" %}`, loc)
+	require.NoError(t, err)
+	target, target2 := filepath.Join("testdata", "include_target.html"), filepath.Join("testdata", "include_target_2.html")
+
+	includes, err := render.RenderWithIncludes(root, ioutil.Discard, map[string]interface{}{"test": false}, config)
+	require.NoError(t, err)
+	require.Equal(t, []string{target2}, includes)
+
+	includes, err = render.RenderWithIncludes(root, ioutil.Discard, map[string]interface{}{"test": true}, config)
+	require.NoError(t, err)
+	require.Equal(t, []string{target2, target}, includes)
+
+	// concurrent renderings with the same config record their includes separately
+	var wg sync.WaitGroup
+	results := make([][]string, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = render.RenderWithIncludes(root, ioutil.Discard, map[string]interface{}{"test": i%2 == 1}, config)
+		}(i)
+	}
+	wg.Wait()
+	for i, includes := range results {
+		if i%2 == 1 {
+			require.Equal(t, []string{target2, target}, includes, i)
+		} else {
+			require.Equal(t, []string{target2}, includes, i)
+		}
+	}
+}
+
+func BenchmarkIncludeTag_loop(b *testing.B) {
+	loc := parser.SourceLoc{Pathname: "testdata/include_source.html", LineNo: 1}
+	bindings := map[string]interface{}{"var": "value", "test": true, "items": make([]int, 100)}
+	for _, cached := range []bool{true, false} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			config := render.NewConfig()
+			if !cached {
+				config.IncludeCache = nil
+			}
+			AddStandardTags(config)
+			root, err := config.Compile(`{% for item in items %}{% include "include_target_2.html" %}{% endfor %}`, loc)
+			require.NoError(b, err)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err = render.Render(root, ioutil.Discard, bindings, config)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"math"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/render"
+	"github.com/osteele/liquid/values"
 )
 
 // An IterationKeyedMap is a map that yields its keys, instead of (key, value) pairs, when iterated.
@@ -26,6 +30,9 @@ type iterable interface {
 	Index(int) interface{}
 }
 
+// breakTag and continueTag return sentinel errors that propagate up through the
+// enclosing blocks until they reach the innermost {% for %} or {% tablerow %}, which
+// consumes them. Liquid has no labeled break, so an outer loop is unaffected.
 func breakTag(string) (func(io.Writer, render.Context) error, error) {
 	return func(_ io.Writer, ctx render.Context) error {
 		return ctx.WrapError(errLoopBreak)
@@ -53,15 +60,30 @@ func cycleTag(args string) (func(io.Writer, render.Context) error, error) {
 		// “C++ protects against accident, not against fraud.” – Bjarne Stroustrup
 		loopRec := loopVar.(map[string]interface{})
 		cycleMap := loopRec[".cycles"].(map[string]int)
-		group, values := cycle.Group, cycle.Values
-		n := cycleMap[group]
-		cycleMap[group] = n + 1
+		values := cycle.Values
+		key := cycleKey(cycle)
+		n := cycleMap[key]
+		cycleMap[key] = n + 1
 		// The parser guarantees that there will be at least one item.
-		_, err = io.WriteString(w, values[n%len(values)])
+		_, err := io.WriteString(w, values[n%len(values)])
 		return err
 	}, nil
 }
 
+// cycleKey returns the key under which a cycle's position is stored.
+// Cycles with the same group name share state; unnamed cycles are keyed by their values.
+//
+// Positions are stored in the innermost enclosing loop, and each rendering of a loop starts
+// with new positions. A group therefore restarts in a sibling loop, and in an inner loop each
+// time an outer loop re-enters it; and a group in an outer loop is independent of the same
+// group in an inner loop.
+func cycleKey(cycle expressions.Cycle) string {
+	if cycle.Group != "" {
+		return "group:" + cycle.Group
+	}
+	return "values:" + strings.Join(cycle.Values, "\x00")
+}
+
 func loopTagCompiler(node render.BlockNode) (func(io.Writer, render.Context) error, error) {
 	stmt, err := expressions.ParseStatement(expressions.LoopStatementSelector, node.Args)
 	if err != nil {
@@ -81,7 +103,10 @@ func (loop loopRenderer) render(w io.Writer, ctx render.Context) error {
 	if err != nil {
 		return err
 	}
-	iter := makeIterator(val)
+	if pager, ok := values.AsPager(val); ok {
+		return loop.renderPages(w, ctx, pager)
+	}
+	iter := makeIterator(val, ctx.Config())
 	if iter == nil {
 		return nil
 	}
@@ -102,10 +127,14 @@ func (loop loopRenderer) render(w io.Writer, ctx render.Context) error {
 		ctx.Set(loop.Variable, forloop)
 	}(ctx.Get(forloopVarName), ctx.Get(loop.Variable))
 	cycleMap := map[string]int{}
+	timer := newLoopTimer(ctx.Config())
 loop:
 	for i, len := 0, iter.Len(); i < len; i++ {
+		if err := ctx.CountIteration(); err != nil {
+			return err
+		}
 		ctx.Set(loop.Variable, iter.Index(i))
-		ctx.Set(forloopVarName, map[string]interface{}{
+		forloop := map[string]interface{}{
 			"first":   i == 0,
 			"last":    i == len-1,
 			"index":   i + 1,
@@ -114,10 +143,16 @@ loop:
 			"rindex0": len - i - 1,
 			"length":  len,
 			".cycles": cycleMap,
-		})
-		decorator.before(w, i)
+		}
+		timer.annotate(forloop)
+		ctx.Set(forloopVarName, forloop)
+		if err := decorator.before(w, i); err != nil {
+			return err
+		}
 		err := ctx.RenderChildren(w)
-		decorator.after(w, i, len)
+		if err := decorator.after(w, i, len); err != nil {
+			return err
+		}
 		switch {
 		case err == nil:
 		// fall through
@@ -132,6 +167,143 @@ loop:
 	return nil
 }
 
+// pagerPageSize is the number of items that a loop over a values.Pager requests at a time.
+const pagerPageSize = 100
+
+// renderPages renders a loop over a values.Pager. Pages are fetched as the loop reaches
+// them, so the length of the collection isn't known: forloop.length, forloop.rindex, and
+// forloop.rindex0 are nil, and the reversed modifier and percentage limits are errors.
+func (loop loopRenderer) renderPages(w io.Writer, ctx render.Context, pager values.Pager) error {
+	if loop.Reversed {
+		return ctx.Errorf("reversed requires a collection with a known length")
+	}
+	offset, limit := 0, -1
+	if loop.Offset != nil {
+		val, err := ctx.Evaluate(loop.Offset)
+		if err != nil {
+			return err
+		}
+		n, ok := val.(int)
+		if !ok {
+			return ctx.Errorf("loop offset must be an integer")
+		}
+		if n > 0 {
+			offset = n
+		}
+	}
+	if loop.Limit != nil {
+		val, err := ctx.Evaluate(loop.Limit)
+		if err != nil {
+			return err
+		}
+		n, ok := val.(int)
+		if !ok {
+			return ctx.Errorf("loop limit over a paged collection must be an integer")
+		}
+		limit = n
+	}
+	decorator, err := makeLoopDecorator(loop, ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func(index, forloop interface{}) {
+		ctx.Set(forloopVarName, index)
+		ctx.Set(loop.Variable, forloop)
+	}(ctx.Get(forloopVarName), ctx.Get(loop.Variable))
+	cycleMap := map[string]int{}
+	timer := newLoopTimer(ctx.Config())
+	// fetch returns the page that starts with item i. This is empty at the end of the
+	// collection or the limit.
+	more := true
+	fetch := func(i int) []interface{} {
+		n := pagerPageSize
+		if limit >= 0 && limit-i < n {
+			n = limit - i
+		}
+		if !more || n <= 0 {
+			return nil
+		}
+		var page []interface{}
+		page, more = pager.Page(offset+i, n)
+		if len(page) > n {
+			page = page[:n]
+		}
+		return page
+	}
+	page := fetch(0)
+loop:
+	for i := 0; len(page) > 0; i++ {
+		if err := ctx.CountIteration(); err != nil {
+			return err
+		}
+		item := page[0]
+		if page = page[1:]; len(page) == 0 {
+			// fetch the next page now, in order to know whether this is the last item
+			page = fetch(i + 1)
+		}
+		last := len(page) == 0
+		ctx.Set(loop.Variable, item)
+		forloop := map[string]interface{}{
+			"first":   i == 0,
+			"last":    last,
+			"index":   i + 1,
+			"index0":  i,
+			".cycles": cycleMap,
+		}
+		timer.annotate(forloop)
+		ctx.Set(forloopVarName, forloop)
+		length := -1
+		if last {
+			length = i + 1
+		}
+		if err := decorator.before(w, i); err != nil {
+			return err
+		}
+		err := ctx.RenderChildren(w)
+		if err := decorator.after(w, i, length); err != nil {
+			return err
+		}
+		switch {
+		case err == nil:
+		// fall through
+		case err.Cause() == errLoopBreak:
+			break loop
+		case err.Cause() == errLoopContinueLoop:
+			continue loop
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// A loopTimer adds forloop.elapsed_ms to the forloop objects of a loop, if the
+// configuration sets LoopTiming. A nil loopTimer does nothing.
+type loopTimer struct {
+	now   func() time.Time
+	start time.Time
+}
+
+func newLoopTimer(cfg render.Config) *loopTimer {
+	if !cfg.LoopTiming {
+		return nil
+	}
+	now := cfg.Clock
+	if now == nil {
+		now = time.Now
+	}
+	return &loopTimer{now, now()}
+}
+
+func (t *loopTimer) annotate(forloop map[string]interface{}) {
+	if t == nil {
+		return
+	}
+	elapsed := t.now().Sub(t.start)
+	forloop["elapsed_ms"] = float64(elapsed) / float64(time.Millisecond)
+}
+
 func makeLoopDecorator(loop loopRenderer, ctx render.Context) (loopDecorator, error) {
 	if loop.tagName == "tablerow" {
 		if loop.Cols != nil {
@@ -153,43 +325,43 @@ func makeLoopDecorator(loop loopRenderer, ctx render.Context) (loopDecorator, er
 }
 
 type loopDecorator interface {
-	before(io.Writer, int)
-	after(io.Writer, int, int)
+	before(io.Writer, int) error
+	after(io.Writer, int, int) error
 }
 
 type forLoopDecorator struct{}
 
-func (d forLoopDecorator) before(io.Writer, int)     {}
-func (d forLoopDecorator) after(io.Writer, int, int) {}
+func (d forLoopDecorator) before(io.Writer, int) error     { return nil }
+func (d forLoopDecorator) after(io.Writer, int, int) error { return nil }
 
 type tableRowDecorator int
 
-func (c tableRowDecorator) before(w io.Writer, i int) {
+func (c tableRowDecorator) before(w io.Writer, i int) error {
 	cols := int(c)
 	row, col := i/cols, i%cols
 	if col == 0 {
 		if _, err := fmt.Fprintf(w, `<tr class="row%d">`, row+1); err != nil {
-			panic(err)
+			return err
 		}
 	}
-	if _, err := fmt.Fprintf(w, `<td class="col%d">`, col+1); err != nil {
-		panic(err)
-	}
+	_, err := fmt.Fprintf(w, `<td class="col%d">`, col+1)
+	return err
 }
 
-func (c tableRowDecorator) after(w io.Writer, i, len int) {
+func (c tableRowDecorator) after(w io.Writer, i, len int) error {
 	cols := int(c)
 	if _, err := io.WriteString(w, `</td>`); err != nil {
-		panic(err)
+		return err
 	}
 	if (i+1)%cols == 0 || i+1 == len {
-		if _, err := io.WriteString(w, `</tr>`); err != nil {
-			panic(err)
-		}
+		_, err := io.WriteString(w, `</tr>`)
+		return err
 	}
+	return nil
 }
 
 func applyLoopModifiers(loop expressions.Loop, ctx render.Context, iter iterable) (iterable, error) {
+	length := iter.Len()
 	if loop.Reversed {
 		iter = reverseWrapper{iter}
 	}
@@ -214,8 +386,11 @@ func applyLoopModifiers(loop expressions.Loop, ctx render.Context, iter iterable
 			return nil, err
 		}
 		limit, ok := val.(int)
+		if s, isString := val.(string); isString {
+			limit, ok = percentageLimit(s, length)
+		}
 		if !ok {
-			return nil, ctx.Errorf("loop limit must be an integer")
+			return nil, ctx.Errorf("loop limit must be an integer or a percentage")
 		}
 		if limit >= 0 {
 			iter = limitWrapper{iter, limit}
@@ -225,7 +400,20 @@ func applyLoopModifiers(loop expressions.Loop, ctx render.Context, iter iterable
 	return iter, nil
 }
 
-func makeIterator(value interface{}) iterable {
+// percentageLimit converts a limit such as "50%" into a count of items, relative to
+// the length of the collection and rounded down.
+func percentageLimit(s string, length int) (int, bool) {
+	if !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	if err != nil || pct < 0 {
+		return 0, false
+	}
+	return int(math.Floor(float64(length) * pct / 100)), true
+}
+
+func makeIterator(value interface{}, cfg render.Config) iterable {
 	if iter, ok := value.(iterable); ok {
 		return iter
 	}
@@ -249,11 +437,50 @@ func makeIterator(value interface{}) iterable {
 			array[i] = []interface{}{k.Interface(), v.Interface()}
 		}
 		return sliceWrapper(reflect.ValueOf(array))
+	case reflect.Struct:
+		return makeStructIterator(reflect.ValueOf(value), cfg.SortStructFields)
+	case reflect.Ptr:
+		rv := reflect.ValueOf(value)
+		if rv.Type().Elem().Kind() == reflect.Struct && !rv.IsNil() {
+			return makeStructIterator(rv.Elem(), cfg.SortStructFields)
+		}
+		return nil
 	default:
 		return nil
 	}
 }
 
+// makeStructIterator yields a [name, value] pair for each exported field of a struct, in
+// declaration order unless sorted is true.
+// A field tagged `liquid:"name"` is yielded with that name; a field tagged `liquid:"-"` is omitted.
+// Otherwise a field tagged `json:"name"` is yielded with that name, as for property access.
+func makeStructIterator(rv reflect.Value, sorted bool) iterable {
+	rt := rv.Type()
+	array := make([][]interface{}, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("liquid"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		} else if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+		array = append(array, []interface{}{name, rv.Field(i).Interface()})
+	}
+	if sorted {
+		sort.SliceStable(array, func(i, j int) bool {
+			return array[i][0].(string) < array[j][0].(string)
+		})
+	}
+	return sliceWrapper(reflect.ValueOf(array))
+}
+
 func makeIterationKeyedMap(m map[string]interface{}) iterable {
 	// Iteration chooses a random start, so we need a copy of the keys to iterate through them.
 	keys := make([]string, 0, len(m))
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -73,14 +74,44 @@ var iterationTests = []struct{ in, expected string }{
 	{`{% for a in array offset:1 %}{{ forloop.first }}.{% endfor %}`, "true.false."},
 	{`{% for a in array offset:1 %}{{ forloop.last }}.{% endfor %}`, "false.true."},
 	{`{% for a in array offset:1 %}{{ forloop.length }}.{% endfor %}`, "2.2."},
+	{`{% for p in products limit: "50%" %}{{ p }}.{% endfor %}`, "Cool Shirt.Alien Poster.Batman Poster."},
+	{`{% for a in array limit: "50%" %}{{ a }}.{% endfor %}`, "first."},
+	{`{% for a in array limit: "100%" %}{{ a }}.{% endfor %}`, "first.second.third."},
+	{`{% for a in array limit: "0%" %}{{ a }}.{% endfor %}`, ""},
+	{`{% for a in array limit: "70%" offset: 1 %}{{ a }}.{% endfor %}`, "second.third."},
+	{`{% for a in array reversed limit: "66.7%" %}{{ a }}.{% endfor %}`, "third.second."},
 
 	{`{% for a in array %}{% if a == 'second' %}{% break %}{% endif %}{{ a }}{% endfor %}`, "first"},
 	{`{% for a in array %}{% if a == 'second' %}{% continue %}{% endif %}{{ a }}.{% endfor %}`, "first.third."},
 
+	// break and continue apply to the innermost enclosing loop
+	{`{% for a in array %}{{ a }}:{% for b in array %}{% if b == 'second' %}{% break %}{% endif %}{{ b }},{% endfor %}.{% endfor %}`,
+		"first:first,.second:first,.third:first,."},
+	{`{% for a in array %}{{ a }}:{% for b in array %}{% if b == 'second' %}{% continue %}{% endif %}{{ b }},{% endfor %}.{% endfor %}`,
+		"first:first,third,.second:first,third,.third:first,third,."},
+	{`{% for a in array %}{% for b in array %}{% if b == a %}{% break %}{% endif %}{{ b }},{% endfor %}{{ forloop.index }}.{% endfor %}`,
+		"1.first,2.first,second,3."},
+	{`{% for a in array %}{% for b in (1..2) %}{{ b }}{% endfor %}{% if a == 'second' %}{% break %}{% endif %}{{ a }}.{% endfor %}`,
+		"12first.12"},
+	{`{% for a in array %}{% for b in (1..2) %}{% unless b == 1 %}{% if a != 'first' %}{% break %}{% endif %}{% endunless %}{{ b }}{% endfor %}{{ a }}.{% endfor %}`,
+		"12first.1second.1third."},
+	{`{% for a in array %}{% for b in (1..3) %}{% for c in (1..3) %}{% if c == 2 %}{% break %}{% endif %}{{ c }}{% endfor %}{% if b == 2 %}{% break %}{% endif %}{{ b }}{% endfor %}.{% endfor %}`,
+		"111.111.111."},
+
 	// cycle
 	{`{% for a in array %}{% cycle 'even', 'odd' %}.{% endfor %}`, "even.odd.even."},
 	{`{% for a in array %}{% cycle '0', '1' %},{% cycle '0', '1' %}.{% endfor %}`, "0,1.0,1.0,1."},
-	// {`{% for a in array %}{% cycle group: 'a', '0', '1' %},{% cycle '0', '1' %}.{% endfor %}`, "0,1.0,1.0,1."},
+	{`{% for a in array %}{% cycle 'a': '0', '1' %},{% cycle '0', '1' %}.{% endfor %}`, "0,0.1,1.0,0."},
+	{`{% for a in array %}{% cycle 'g': 'x', 'y' %}{% cycle 'h': 'x', 'y', 'z' %}{% cycle 'g': 'x', 'y' %}.{% endfor %}`, "xxy.xyy.xzy."},
+	{`{% for a in array %}{% cycle 'g': 'a', 'b' %}{% cycle 'g': 'c', 'd' %}.{% endfor %}`, "ad.ad.ad."},
+	{`{% for a in array %}{% cycle 'a', 'b' %}{% cycle 'c', 'd', 'e' %}.{% endfor %}`, "ac.bd.ae."},
+	// named cycles and loop instances
+	{`{% for a in array %}{% cycle 'x', 'y' %}{% endfor %}.{% for a in array %}{% cycle 'x', 'y' %}{% endfor %}`, "xyx.xyx"},
+	{`{% for a in (1..2) %}{% cycle 'x', 'y' %}{% endfor %}.{% for a in (1..2) %}{% cycle 'x', 'y' %}{% endfor %}`, "xy.xy"},
+	{`{% for a in array %}{% cycle 'g': 'x', 'y' %}{% endfor %}.{% for a in array %}{% cycle 'g': 'x', 'y' %}{% endfor %}`, "xyx.xyx"},
+	{`{% for a in (1..2) %}{% for b in array %}{% cycle 'g': 'x', 'y' %}{% endfor %}.{% endfor %}`, "xyx.xyx."},
+	{`{% for a in (1..2) %}{% for b in (1..2) %}{% cycle 'x', 'y', 'z' %}{% endfor %}.{% endfor %}`, "xy.xy."},
+	{`{% for a in array %}{% cycle 'g': 'a', 'b' %}{% for b in (1..2) %}{% cycle 'g': 'x', 'y' %}{% endfor %}.{% endfor %}`, "axy.bxy.axy."},
 
 	// range
 	{`{% for i in (3 .. 5) %}{{i}}.{% endfor %}`, "3.4.5."},
@@ -124,6 +155,8 @@ var iterationErrorTests = []struct{ in, expected string }{
 	{`{% break %}`, "break outside a loop"},
 	{`{% continue %}`, "continue outside a loop"},
 	{`{% cycle 'a', 'b' %}`, "cycle must be within a forloop"},
+	{`{% for a in array limit: "half" %}{% endfor %}`, "loop limit must be an integer or a percentage"},
+	{`{% for a in array limit: "-50%" %}{% endfor %}`, "loop limit must be an integer or a percentage"},
 	{`{% for a in array | undefined_filter %}{% endfor %}`, "undefined filter"},
 	{`{% for a in array %}{{ a | undefined_filter }}{% endfor %}`, "undefined filter"},
 }
@@ -164,6 +197,50 @@ func TestIterationTags(t *testing.T) {
 	}
 }
 
+type iterationTestStruct struct {
+	Zeta    int
+	Alpha   string
+	Renamed bool `liquid:"middle"`
+	Omitted int  `liquid:"-"`
+	JSON    int  `json:"beta,omitempty"`
+	private int
+}
+
+func TestIterationTags_struct(t *testing.T) {
+	bindings := map[string]interface{}{
+		"s": iterationTestStruct{Zeta: 1, Alpha: "a", Renamed: true, Omitted: 2, JSON: 5, private: 3},
+		"p": &iterationTestStruct{Zeta: 4, Alpha: "b"},
+	}
+	tests := []struct {
+		in               string
+		declared, sorted string
+	}{
+		{`{% for f in s %}{{ f[0] }}={{ f[1] }}.{% endfor %}`, "Zeta=1.Alpha=a.middle=true.beta=5.", "Alpha=a.Zeta=1.beta=5.middle=true."},
+		{`{% for f in p %}{{ f[0] }}={{ f[1] }}.{% endfor %}`, "Zeta=4.Alpha=b.middle=false.beta=0.", "Alpha=b.Zeta=4.beta=0.middle=false."},
+		{`{% for f in s reversed limit: 1 %}{{ f[0] }}.{% endfor %}`, "beta.", "middle."},
+		{`{% for f in s %}{% assign name = f[0] %}{% if s[name] == f[1] %}{{ name }}.{% endif %}{% endfor %}`, "Zeta.Alpha.middle.beta.", "Alpha.Zeta.beta.middle."},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			config := render.NewConfig()
+			AddStandardTags(config)
+			root, err := config.Compile(test.in, parser.SourceLoc{})
+			require.NoErrorf(t, err, test.in)
+
+			buf := new(bytes.Buffer)
+			err = render.Render(root, buf, bindings, config)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.declared, buf.String(), test.in)
+
+			config.SortStructFields = true
+			buf = new(bytes.Buffer)
+			err = render.Render(root, buf, bindings, config)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.sorted, buf.String(), test.in)
+		})
+	}
+}
+
 func TestIterationTags_errors(t *testing.T) {
 	cfg := render.NewConfig()
 	AddStandardTags(cfg)
@@ -186,3 +263,161 @@ func TestIterationTags_errors(t *testing.T) {
 		})
 	}
 }
+
+// iterationTestPager yields the items 1..n, at most pageSize at a time, and records its calls.
+type iterationTestPager struct {
+	n, pageSize int
+	calls       []string
+}
+
+func (p *iterationTestPager) Page(offset, limit int) ([]interface{}, bool) {
+	p.calls = append(p.calls, fmt.Sprintf("%d,%d", offset, limit))
+	if limit > p.pageSize {
+		limit = p.pageSize
+	}
+	page := []interface{}{}
+	for i := offset; i < offset+limit && i < p.n; i++ {
+		page = append(page, i+1)
+	}
+	return page, offset+len(page) < p.n
+}
+
+func TestIterationTags_pager(t *testing.T) {
+	tests := []struct {
+		in, expected string
+		calls        []string
+	}{
+		{`{% for n in pager %}{{ n }}.{% endfor %}`, "1.2.3.4.5.", []string{"0,100", "3,100"}},
+		{`{% for n in pager %}{{ forloop.index }}{% if forloop.first %}f{% endif %}{% if forloop.last %}l{% endif %}[{{ forloop.length }}].{% endfor %}`,
+			"1f[].2[].3[].4[].5l[].", []string{"0,100", "3,100"}},
+		{`{% for n in pager limit: 2 %}{{ n }}.{% endfor %}`, "1.2.", []string{"0,2"}},
+		{`{% for n in pager offset: 1 limit: 3 %}{{ n }}{% if forloop.last %}l{% endif %}.{% endfor %}`, "2.3.4l.", []string{"1,3"}},
+		{`{% for n in pager %}{% if n == 2 %}{% break %}{% endif %}{{ n }}.{% endfor %}`, "1.", []string{"0,100"}},
+		{`{% for n in pager %}{% if n == 2 %}{% continue %}{% endif %}{{ n }}.{% endfor %}`, "1.3.4.5.", []string{"0,100", "3,100"}},
+		{`{% for n in pager %}{% cycle 'a', 'b' %}{% endfor %}`, "ababa", []string{"0,100", "3,100"}},
+		{`{% tablerow n in pager cols: 2 %}{{ n }}{% endtablerow %}`,
+			`<tr class="row1"><td class="col1">1</td><td class="col2">2</td></tr><tr class="row2"><td class="col1">3</td><td class="col2">4</td></tr><tr class="row3"><td class="col1">5</td></tr>`,
+			[]string{"0,100", "3,100"}},
+	}
+	config := render.NewConfig()
+	AddStandardTags(config)
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			pager := &iterationTestPager{n: 5, pageSize: 3}
+			root, err := config.Compile(test.in, parser.SourceLoc{})
+			require.NoErrorf(t, err, test.in)
+			buf := new(bytes.Buffer)
+			err = render.Render(root, buf, map[string]interface{}{"pager": pager}, config)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, buf.String(), test.in)
+			require.Equalf(t, test.calls, pager.calls, test.in)
+		})
+	}
+
+	// a function with the signature of values.PagerFunc is also a pager
+	pages := [][]interface{}{{"a", "b"}, {"c"}}
+	fn := func(offset, limit int) ([]interface{}, bool) {
+		page := pages[0]
+		pages = pages[1:]
+		return page, len(pages) > 0
+	}
+	root, err := config.Compile(`{% for x in fn %}{{ x }}{% endfor %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, map[string]interface{}{"fn": fn}, config)
+	require.NoError(t, err)
+	require.Equal(t, "abc", buf.String())
+
+	root, err = config.Compile(`{% for n in pager reversed %}{{ n }}{% endfor %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	err = render.Render(root, ioutil.Discard, map[string]interface{}{"pager": &iterationTestPager{n: 5, pageSize: 3}}, config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "reversed requires a collection with a known length")
+}
+
+func TestIterationTags_LoopTiming(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	src := `{% for a in array %}{{ forloop.elapsed_ms }},{% endfor %}`
+	root, err := config.Compile(src, parser.SourceLoc{})
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, render.Render(root, buf, iterationTestBindings, config))
+	require.Equal(t, ",,,", buf.String())
+
+	now := time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)
+	config.LoopTiming = true
+	config.Clock = func() time.Time {
+		now = now.Add(1500 * time.Microsecond)
+		return now
+	}
+	buf = new(bytes.Buffer)
+	require.NoError(t, render.Render(root, buf, iterationTestBindings, config))
+	require.Equal(t, "1.5,3.0,4.5,", buf.String())
+}
+
+func TestIterationTags_MaxIterations(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	config.MaxIterations = 1000
+	for _, test := range []struct {
+		in string
+		ok bool
+	}{
+		{`{% for i in (1..1000) %}{% endfor %}`, true},
+		{`{% for i in (1..10) %}{% endfor %}{% for i in (1..990) %}{% endfor %}`, true},
+		{`{% for i in (1..1000000) %}{% endfor %}`, false},
+		{`{% for i in (1..10) %}{% for j in (1..100) %}{% endfor %}{% endfor %}`, false},
+		{`{% for i in (1..600) %}{% endfor %}{% tablerow i in (1..600) %}{% endtablerow %}`, false},
+	} {
+		root, err := config.Compile(test.in, parser.SourceLoc{})
+		require.NoErrorf(t, err, test.in)
+		err = render.Render(root, ioutil.Discard, iterationTestBindings, config)
+		if test.ok {
+			require.NoErrorf(t, err, test.in)
+			continue
+		}
+		require.Errorf(t, err, test.in)
+		require.Containsf(t, err.Error(), "loop iterations exceed the limit of 1000", test.in)
+	}
+}
+
+func TestIterationTags_MaxOutputSize(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	config.MaxOutputSize = 1000
+	src := `{% assign s = "x" %}{% for i in (1..20) %}{% capture s %}{{ s }}{{ s }}{% endcapture %}{% endfor %}{{ s }}`
+	root, err := config.Compile(src, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, iterationTestBindings, config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "output exceeds the limit of 1000 bytes")
+	require.Equal(t, 0, buf.Len())
+
+	src = `{% for i in (1..1000000) %}{{ i }}{% endfor %}`
+	root, err = config.Compile(src, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf = new(bytes.Buffer)
+	err = render.Render(root, buf, iterationTestBindings, config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "output exceeds the limit of 1000 bytes")
+	require.LessOrEqual(t, buf.Len(), 1000)
+
+	// the tablerow markup is also limited
+	config.MaxOutputSize = 10
+	for _, src := range []string{
+		`{% tablerow i in (1..3) %}{% endtablerow %}`,
+		`{% tablerow i in (1..3) cols: 2 %}{{ i }}{% endtablerow %}`,
+		`{% tablerow i in (1..3) %}{% break %}{% endtablerow %}`,
+	} {
+		root, err = config.Compile(src, parser.SourceLoc{})
+		require.NoError(t, err)
+		buf = new(bytes.Buffer)
+		require.NotPanics(t, func() { err = render.Render(root, buf, iterationTestBindings, config) }, src)
+		require.Errorf(t, err, src)
+		require.Containsf(t, err.Error(), "output exceeds the limit of 10 bytes", src)
+		require.LessOrEqual(t, buf.Len(), 10)
+	}
+}
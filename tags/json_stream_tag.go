@@ -0,0 +1,53 @@
+package tags
+
+import (
+	"io"
+
+	"github.com/osteele/liquid/expressions"
+	"github.com/osteele/liquid/render"
+	"github.com/osteele/liquid/values"
+)
+
+// jsonStreamTag implements {% json_stream collection %}. It writes the collection as a JSON
+// array, one element at a time, so that the array as a whole is never held in memory.
+// A nil collection is written as an empty array. Elements are encoded as the jsonify filter
+// encodes them.
+func jsonStreamTag(source string) (func(io.Writer, render.Context) error, error) {
+	expr, err := expressions.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer, ctx render.Context) error {
+		value, err := ctx.Evaluate(expr)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			_, err = io.WriteString(w, "[]")
+			return err
+		}
+		iter := makeIterator(value, ctx.Config())
+		if iter == nil {
+			return ctx.Errorf("json_stream requires an array; got %T", value)
+		}
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		for i := 0; i < iter.Len(); i++ {
+			if i > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			b, err := values.ToJSON(iter.Index(i))
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, "]")
+		return err
+	}, nil
+}
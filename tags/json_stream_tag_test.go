@@ -0,0 +1,100 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/osteele/liquid/parser"
+	"github.com/osteele/liquid/render"
+	"github.com/stretchr/testify/require"
+)
+
+var jsonStreamTests = []struct{ in, expected string }{
+	{`{% json_stream animals %}`, `["zebra","octopus","giraffe","Sally Snake"]`},
+	{`{% json_stream obj.missing %}`, `[]`},
+	{`{% json_stream (1..3) %}`, `[1,2,3]`},
+	{`{% json_stream sort_prop %}`, `[{"weight":1},{"weight":5},{"weight":3},{"weight":null}]`},
+}
+
+// countingWriter records the number of calls to Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(b)
+}
+
+func TestJSONStreamTag(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	for i, test := range jsonStreamTests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			root, err := config.Compile(test.in, parser.SourceLoc{})
+			require.NoErrorf(t, err, test.in)
+			buf := new(bytes.Buffer)
+			err = render.Render(root, buf, tagTestBindings, config)
+			require.NoErrorf(t, err, test.in)
+			require.Equalf(t, test.expected, buf.String(), test.in)
+		})
+	}
+
+	root, err := config.Compile(`{% json_stream x %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	err = render.Render(root, new(bytes.Buffer), tagTestBindings, config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "json_stream requires an array")
+}
+
+func TestJSONStreamTag_large(t *testing.T) {
+	const n = 5000
+	items := make([]map[string]interface{}, n)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("item %d", i)}
+	}
+	config := render.NewConfig()
+	AddStandardTags(config)
+	root, err := config.Compile(`{% json_stream items %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+
+	w := new(countingWriter)
+	err = render.Render(root, w, map[string]interface{}{"items": items}, config)
+	require.NoError(t, err)
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &decoded))
+	require.Len(t, decoded, n)
+	require.Equal(t, "item 4999", decoded[n-1]["name"])
+	// the elements are written one at a time, rather than as a single array
+	require.True(t, w.writes >= n, "expected at least %d writes; got %d", n, w.writes)
+}
+
+type jsonStreamTestDrop struct{ proxy interface{} }
+
+func (d jsonStreamTestDrop) ToLiquid() interface{} { return d.proxy }
+
+type jsonStreamTestStruct struct {
+	Name string `json:"name"`
+	Size int    `json:"size,omitempty"`
+}
+
+func TestJSONStreamTag_drops(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	root, err := config.Compile(`{% json_stream items %}`, parser.SourceLoc{})
+	require.NoError(t, err)
+
+	items := []interface{}{
+		jsonStreamTestDrop{"a"},
+		jsonStreamTestStruct{Name: "b"},
+		map[string]interface{}{"drop": jsonStreamTestDrop{jsonStreamTestStruct{Name: "c", Size: 1}}},
+	}
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, map[string]interface{}{"items": items}, config)
+	require.NoError(t, err)
+	require.Equal(t, `["a",{"name":"b"},{"drop":{"name":"c","size":1}}]`, buf.String())
+}
@@ -2,16 +2,22 @@
 package tags
 
 import (
+	"fmt"
 	"io"
+	"reflect"
+	"strings"
 
 	"github.com/osteele/liquid/expressions"
 	"github.com/osteele/liquid/render"
+	"github.com/osteele/liquid/values"
 )
 
 // AddStandardTags defines the standard Liquid tags.
 func AddStandardTags(c render.Config) {
+	c.AddTag("assert", assertTag)
 	c.AddTag("assign", assignTag)
 	c.AddTag("include", includeTag)
+	c.AddTag("json_stream", jsonStreamTag)
 
 	// blocks
 	// The parser only recognize the comment and raw tags if they've been defined,
@@ -26,7 +32,43 @@ func AddStandardTags(c render.Config) {
 	c.AddBlock("if").Clause("else").Clause("elsif").Compiler(ifTagCompiler(true))
 	c.AddBlock("raw")
 	c.AddBlock("tablerow").Compiler(loopTagCompiler)
-	c.AddBlock("unless").Clause("else").Compiler(ifTagCompiler(false))
+	c.AddBlock("unless").Clause("else").Clause("elsif").Compiler(ifTagCompiler(false))
+}
+
+// assertTag implements {% assert condition, "message" %}. It renders nothing if the
+// condition is truthy, and otherwise fails the render with the message.
+func assertTag(source string) (func(io.Writer, render.Context) error, error) {
+	parts := splitTopLevel(source, ",")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("assert requires a condition and an optional message")
+	}
+	cond, err := expressions.Parse(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	var message expressions.Expression
+	if len(parts) > 1 {
+		if message, err = expressions.Parse(parts[1]); err != nil {
+			return nil, err
+		}
+	}
+	return func(w io.Writer, ctx render.Context) error {
+		value, err := ctx.Evaluate(cond)
+		if err != nil {
+			return err
+		}
+		if value != nil && value != false {
+			return nil
+		}
+		if message == nil {
+			return ctx.Errorf("assertion failed: %s", strings.TrimSpace(parts[0]))
+		}
+		msg, err := ctx.Evaluate(message)
+		if err != nil {
+			return err
+		}
+		return ctx.Errorf("%v", msg)
+	}, nil
 }
 
 func assignTag(source string) (func(io.Writer, render.Context) error, error) {
@@ -39,12 +81,124 @@ func assignTag(source string) (func(io.Writer, render.Context) error, error) {
 		if err != nil {
 			return err
 		}
-		_ = value
+		if err := checkVariableSize(ctx, stmt.Assignment.Variable, value); err != nil {
+			return err
+		}
+		if len(stmt.Assignment.Indices) > 0 {
+			value, err = assignElement(ctx, stmt.Assignment, value)
+			if err != nil {
+				return err
+			}
+		}
 		ctx.Set(stmt.Assignment.Variable, value)
 		return nil
 	}, nil
 }
 
+// assignElement implements an assignment such as {% assign a.b[0] = value %}. It returns the
+// new value of the variable.
+func assignElement(ctx render.Context, stmt expressions.Assignment, value interface{}) (interface{}, error) {
+	container := ctx.Get(stmt.Variable)
+	if container == nil {
+		return nil, ctx.Errorf("can't assign to an element of %q, which is undefined", stmt.Variable)
+	}
+	indices := make([]interface{}, len(stmt.Indices))
+	for i, expr := range stmt.Indices {
+		index, err := ctx.Evaluate(expr)
+		if err != nil {
+			return nil, err
+		}
+		indices[i] = index
+	}
+	result, err := setElement(reflect.ValueOf(container), indices, value)
+	if err != nil {
+		return nil, ctx.Errorf("can't assign to an element of %q: %s", stmt.Variable, err)
+	}
+	return result.Interface(), nil
+}
+
+// setElement returns a copy of the map, slice, or array container, in which the element at the
+// path of indices is value. The copy is shallow, except along the path, so that the assignment
+// doesn't modify the values that the template is rendered with.
+func setElement(container reflect.Value, indices []interface{}, value interface{}) (reflect.Value, error) {
+	index, rest := indices[0], indices[1:]
+	if container.Kind() == reflect.Interface {
+		container = container.Elem()
+	}
+	var (
+		result reflect.Value
+		elem   func() reflect.Value // the existing element
+		set    func(reflect.Value)
+	)
+	switch container.Kind() {
+	case reflect.Map:
+		key, err := convertElement(index, container.Type().Key())
+		if err != nil {
+			return result, err
+		}
+		result = reflect.MakeMapWithSize(container.Type(), container.Len()+1)
+		iter := container.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+		elem = func() reflect.Value { return container.MapIndex(key) }
+		set = func(v reflect.Value) { result.SetMapIndex(key, v) }
+	case reflect.Array, reflect.Slice:
+		n, ok := index.(int)
+		if !ok {
+			return result, fmt.Errorf("the index %v is not an integer", index)
+		}
+		if n < 0 {
+			n += container.Len()
+		}
+		if n < 0 || n >= container.Len() {
+			return result, fmt.Errorf("the index %v is out of range", index)
+		}
+		result = reflect.New(container.Type()).Elem()
+		if container.Kind() == reflect.Slice {
+			result.Set(reflect.MakeSlice(container.Type(), container.Len(), container.Len()))
+		}
+		reflect.Copy(result, container)
+		elem = func() reflect.Value { return container.Index(n) }
+		set = func(v reflect.Value) { result.Index(n).Set(v) }
+	default:
+		return result, fmt.Errorf("a %T doesn't have elements", container.Interface())
+	}
+	var v reflect.Value
+	if len(rest) > 0 {
+		ev := elem()
+		if !ev.IsValid() {
+			return result, fmt.Errorf("the element %v is undefined", index)
+		}
+		var err error
+		if v, err = setElement(ev, rest, value); err != nil {
+			return result, err
+		}
+		value = v.Interface()
+	}
+	v, err := convertElement(value, container.Type().Elem())
+	if err != nil {
+		return result, err
+	}
+	set(v)
+	return result, nil
+}
+
+// convertElement converts value to a map key or an element of type typ.
+func convertElement(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(typ), nil
+	}
+	if rv := reflect.ValueOf(value); rv.Type().AssignableTo(typ) {
+		return rv, nil
+	}
+	c, err := values.Convert(value, typ)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(c), nil
+}
+
 func captureTagCompiler(node render.BlockNode) (func(io.Writer, render.Context) error, error) {
 	// TODO verify syntax
 	varname := node.Args
@@ -53,7 +207,19 @@ func captureTagCompiler(node render.BlockNode) (func(io.Writer, render.Context)
 		if err != nil {
 			return err
 		}
+		if err := checkVariableSize(ctx, varname, s); err != nil {
+			return err
+		}
 		ctx.Set(varname, s)
 		return nil
 	}, nil
 }
+
+// checkVariableSize returns an error if value is a string that is longer than the configured MaxVariableSize.
+func checkVariableSize(ctx render.Context, name string, value interface{}) error {
+	max := ctx.Config().MaxVariableSize
+	if s, ok := value.(string); ok && max > 0 && len(s) > max {
+		return ctx.Errorf("the value of %q is %d bytes, which exceeds the maximum variable size of %d bytes", name, len(s), max)
+	}
+	return nil
+}
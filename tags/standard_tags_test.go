@@ -2,7 +2,9 @@ package tags
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"testing"
 
@@ -15,28 +17,59 @@ var parseErrorTests = []struct{ in, expected string }{
 	{"{% undefined_tag %}", "undefined tag"},
 	{"{% assign v x y z %}", "syntax error"},
 	{"{% if syntax error %}", `unterminated "if" block`},
+	{`{% assert x, "a", "b" %}`, "assert requires a condition"},
 	// TODO once expression parsing is moved to template parse stage
 	// {"{% if syntax error %}{% endif %}", "syntax error"},
 	// {"{% for a in ar undefined %}{{ a }} {% endfor %}", "TODO"},
 }
 
 var tagTests = []struct{ in, expected string }{
+	// assertions
+	{`{% assert x > 100, "x is too small" %}ok`, "ok"},
+	{`{% assert obj.a %}{% assert animals contains "zebra", page.title %}ok`, "ok"},
+
 	// variable tags
 	{`{% assign av = 1 %}{{ av }}`, "1"},
 	{`{% assign av = obj.a %}{{ av }}`, "1"},
 	{`{% assign av = (1..5) %}{{ av }}`, "{1 5}"},
+	{`{% assign found = animals contains "zebra" %}{{ found }}`, "true"},
+	{`{% assign found = (animals contains "lion") %}{{ found }}`, "false"},
+	{`{% assign big = x > 100 %}{% if big %}big{% endif %}`, "big"},
+	{`{% assign both = x > 100 and obj.a == 1 %}{{ both }}`, "true"},
+	{`{% assign either = x < 100 or obj.a != 1 %}{{ either }}`, "false"},
 	{`{% capture x %}captured{% endcapture %}{{ x }}`, "captured"},
+	{`{% assign a = 1 %}{% assign b = a %}{% assign a = 2 %}{{ a }}{{ b }}`, "21"},
+	{`{% assign obj.a = 2 %}{{ obj.a }}`, "2"},
+	{`{% assign obj.b = "new" %}{{ obj.b }}{{ obj.a }}`, "new1"},
+	{`{% assign obj["a"] = x %}{{ obj.a }}`, "123"},
+	{`{% assign animals[0] = "aardvark" %}{{ animals[0] }} {{ animals[1] }}`, "aardvark octopus"},
+	{`{% assign animals[-1] = "snake" %}{{ animals[3] }}`, "snake"},
+	{`{% assign pages[0].category = "news" %}{{ pages[0].category }} {{ pages[1].category }}`, "news celebrities"},
+	{`{% for i in (1..3) %}{% assign obj.a = i %}{% endfor %}{{ obj.a }}`, "3"},
 
-	// TODO research whether Liquid requires matching interior tags
+	// comment discards its contents without parsing them
 	{`{% comment %}{{ a }}{% undefined_tag %}{% endcomment %}`, ""},
+	{`pre{% comment %}{% if %}{{ a | }}{% endfor %}{% else %}{% endcomment %}post`, "prepost"},
+	{`pre{% comment %}a{% comment %}{% if %}b{% endcomment %}c{% endcomment %}post`, "prepost"},
 
-	// TODO research whether Liquid requires matching interior tags
+	// raw outputs its contents verbatim, up to the first {% endraw %} without arguments
 	{`pre{% raw %}{{ a }}{% undefined_tag %}{% endraw %}post`, "pre{{ a }}{% undefined_tag %}post"},
 	{`pre{% raw %}{% if false %}anyway-{% endraw %}post`, "pre{% if false %}anyway-post"},
+	{`pre{% raw %}{{ a {% raw %}}} endraw {% endraw a %}{%endraw%}post`, "pre{{ a {% raw %}}} endraw {% endraw a %}post"},
+	{`pre {% raw %} {{ a }} {%- endraw -%} post`, "pre  {{ a }}post"},
+	{`pre {%- raw -%} {{ a }} {% endraw %} post`, "pre{{ a }}  post"},
+	{"pre\n{%- raw -%}\n {{ a }}\n{%- endraw -%}\n post", "pre{{ a }}post"},
 }
 
 var tagErrorTests = []struct{ in, expected string }{
+	{`{% assert x < 100, "x is too large" %}`, "x is too large"},
+	{`{% assert missing %}`, "assertion failed: missing"},
 	{`{% assign av = x | undefined_filter %}`, "undefined filter"},
+	{`{% assign missing.a = 1 %}`, `can't assign to an element of "missing", which is undefined`},
+	{`{% assign x.a = 1 %}`, "a int doesn't have elements"},
+	{`{% assign animals[10] = 1 %}`, "the index 10 is out of range"},
+	{`{% assign animals["a"] = 1 %}`, "the index a is not an integer"},
+	{`{% assign obj.b.c = 1 %}`, "the element b is undefined"},
 }
 
 // this is also used in the other test files
@@ -45,7 +78,10 @@ var tagTestBindings = map[string]interface{}{
 	"obj": map[string]interface{}{
 		"a": 1,
 	},
-	"animals": []string{"zebra", "octopus", "giraffe", "Sally Snake"},
+	"animals":      []string{"zebra", "octopus", "giraffe", "Sally Snake"},
+	"empty_string": "",
+	"empty_array":  []string{},
+	"empty_map":    map[string]interface{}{},
 	"pages": []map[string]interface{}{
 		{"category": "business"},
 		{"category": "celebrities"},
@@ -107,3 +143,145 @@ func TestStandardTags_render_errors(t *testing.T) {
 		})
 	}
 }
+
+func TestStandardTags_assert(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	root, err := config.Compile("line 1\n{% assert page.author, \"page.author is required\" %}", parser.SourceLoc{Pathname: "page.html", LineNo: 1})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, tagTestBindings, config)
+	require.Error(t, err)
+	var re *render.RenderError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, "page.html", re.Path())
+	require.Equal(t, 2, re.LineNumber())
+	require.Contains(t, err.Error(), "page.author is required")
+}
+
+func TestStandardTags_assignElement(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	obj := map[string]interface{}{"a": 1}
+	list := []int{1, 2, 3}
+	bindings := map[string]interface{}{"obj": obj, "list": list}
+
+	src := `{% assign obj.a = 2 %}{% assign obj.list = list %}{% assign obj.list[1] = "20" %}{% assign list[0] = 10 %}` +
+		`{{ obj.a }} {{ obj.list[1] }} {{ list[0] }} {{ list[1] }}`
+	root, err := config.Compile(src, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, render.Render(root, buf, bindings, config))
+	require.Equal(t, "2 20 10 2", buf.String())
+
+	// the assignments don't modify the values that the template was rendered with
+	require.Equal(t, map[string]interface{}{"a": 1}, obj)
+	require.Equal(t, []int{1, 2, 3}, list)
+}
+
+func TestStandardTags_MaxVariableSize(t *testing.T) {
+	config := render.NewConfig()
+	config.MaxVariableSize = 10
+	AddStandardTags(config)
+
+	tests := []struct{ in, expected string }{
+		{`{% capture x %}{% for a in animals %}{{ a }}{% endfor %}{% endcapture %}`, `the value of "x" is 30 bytes, which exceeds the maximum variable size of 10 bytes`},
+		{`{% assign y = "Ground control to Major Tom" %}`, `the value of "y" is 27 bytes`},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			root, err := config.Compile(test.in, parser.SourceLoc{})
+			require.NoErrorf(t, err, test.in)
+			err = render.Render(root, ioutil.Discard, tagTestBindings, config)
+			require.Errorf(t, err, test.in)
+			require.Containsf(t, err.Error(), test.expected, test.in)
+		})
+	}
+
+	root, err := config.Compile(`{% capture x %}short{% endcapture %}{% assign y = animals %}{{ x }} {{ y.size }}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, tagTestBindings, config)
+	require.NoError(t, err)
+	require.Equal(t, "short 4", buf.String())
+}
+
+func TestStandardTags_TrimBlocks(t *testing.T) {
+	src := "<ul>\n  {% for a in animals limit: 2 %}\n  <li>{{ a }}</li>\n  {% endfor %}\n</ul>\n{% raw %}\n  {% x %}\n{% endraw %}"
+	tests := []struct {
+		trim, lstrip bool
+		expected     string
+	}{
+		{false, false, "<ul>\n  \n  <li>zebra</li>\n  \n  <li>octopus</li>\n  \n</ul>\n\n  {% x %}\n"},
+		{true, false, "<ul>\n    <li>zebra</li>\n    <li>octopus</li>\n  </ul>\n\n  {% x %}\n"},
+		{false, true, "<ul>\n\n  <li>zebra</li>\n\n  <li>octopus</li>\n\n</ul>\n\n  {% x %}\n"},
+		{true, true, "<ul>\n  <li>zebra</li>\n  <li>octopus</li>\n</ul>\n\n  {% x %}\n"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i+1), func(t *testing.T) {
+			config := render.NewConfig()
+			config.TrimBlocks = test.trim
+			config.LstripBlocks = test.lstrip
+			AddStandardTags(config)
+			root, err := config.Compile(src, parser.SourceLoc{})
+			require.NoError(t, err)
+			buf := new(bytes.Buffer)
+			err = render.Render(root, buf, tagTestBindings, config)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, buf.String())
+		})
+	}
+
+	// {%- and -%} still apply
+	config := render.NewConfig()
+	config.TrimBlocks = true
+	AddStandardTags(config)
+	root, err := config.Compile("a  {%- assign x = 1 %}\nb {% assign y = 2 -%}  \nc", parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, render.Render(root, buf, tagTestBindings, config))
+	require.Equal(t, "ab c", buf.String())
+}
+
+func TestStandardTags_parseTreeTagArgs(t *testing.T) {
+	config := render.NewConfig()
+	AddStandardTags(config)
+	config.AddTag("custom", func(string) (func(io.Writer, render.Context) error, error) { return nil, nil })
+	src := `{% assign  total = x | plus: 1 %}{% for a in animals limit: 2 %}{% if a %}{% custom  a, "b" %}{% else %}{% endif %}{% endfor %}`
+	root, err := config.Parse(src, parser.SourceLoc{})
+	require.NoError(t, err)
+	var tags []string
+	parser.Walk(root, func(n parser.ASTNode) {
+		if tag, ok := n.(parser.TagNode); ok {
+			tags = append(tags, tag.TagName()+"("+tag.TagArgs()+")")
+		}
+	})
+	require.Equal(t, []string{
+		"assign(total = x | plus: 1)",
+		"for(a in animals limit: 2)",
+		"if(a)",
+		`custom(a, "b")`,
+		"else()",
+	}, tags)
+}
+
+func TestStandardTags_SnapshotBindings(t *testing.T) {
+	config := render.NewConfig()
+	config.SnapshotBindings = true
+	AddStandardTags(config)
+
+	bindings := map[string]interface{}{
+		"x":   "original",
+		"obj": map[string]interface{}{"a": []interface{}{1, 2}},
+	}
+	root, err := config.Compile(`{% assign x = "assigned" %}{% capture obj %}captured{% endcapture %}{{ x }} {{ obj }}`, parser.SourceLoc{})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	err = render.Render(root, buf, bindings, config)
+	require.NoError(t, err)
+	require.Equal(t, "assigned captured", buf.String())
+	require.Equal(t, map[string]interface{}{
+		"x":   "original",
+		"obj": map[string]interface{}{"a": []interface{}{1, 2}},
+	}, bindings)
+}
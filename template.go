@@ -11,6 +11,11 @@ import (
 // A Template is a compiled Liquid template. It knows how to evaluate itself within a variable binding environment, to create a rendered byte slice.
 //
 // Use Engine.ParseTemplate to create a template.
+//
+// A Template can be rendered concurrently from multiple goroutines. The state of a rendering,
+// such as the variables that it assigns and the loop and cycle counters, belongs to that
+// rendering, so that concurrent renderings with different bindings are independent.
+// The engine must not be reconfigured while its templates are rendered.
 type Template struct {
 	root render.Node
 	cfg  *render.Config
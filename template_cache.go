@@ -0,0 +1,89 @@
+package liquid
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultTemplateCacheSize is the number of compiled templates that an Engine's
+// RenderTemplateString caches, unless SetTemplateCacheSize changes it.
+const DefaultTemplateCacheSize = 100
+
+// A templateCache is a least-recently-used cache of compiled templates, keyed by their
+// source. The zero value is a cache of DefaultTemplateCacheSize templates. A templateCache
+// is safe for concurrent use.
+type templateCache struct {
+	mu      sync.Mutex
+	size    int // the maximum number of entries, if set is true
+	set     bool
+	entries map[string]*list.Element
+	order   list.List // of *templateCacheEntry, most recently used first
+}
+
+type templateCacheEntry struct {
+	source string
+	tpl    *Template
+}
+
+func (c *templateCache) capacity() int {
+	if !c.set {
+		return DefaultTemplateCacheSize
+	}
+	return c.size
+}
+
+// setSize sets the maximum number of entries, and removes the existing entries.
+func (c *templateCache) setSize(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size, c.set = n, true
+	c.entries = nil
+	c.order.Init()
+}
+
+func (c *templateCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.order.Init()
+}
+
+func (c *templateCache) get(source string) (*Template, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[source]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*templateCacheEntry).tpl, true
+}
+
+func (c *templateCache) put(source string, tpl *Template) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	capacity := c.capacity()
+	if capacity <= 0 {
+		return
+	}
+	if el, ok := c.entries[source]; ok {
+		el.Value.(*templateCacheEntry).tpl = tpl
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+	}
+	c.entries[source] = c.order.PushFront(&templateCacheEntry{source, tpl})
+	for c.order.Len() > capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*templateCacheEntry).source)
+	}
+}
+
+func (c *templateCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
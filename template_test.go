@@ -2,6 +2,8 @@ package liquid
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"testing"
 
@@ -102,6 +104,58 @@ func TestTemplate_Render_race(t *testing.T) {
 	wg2.Wait()
 }
 
+func TestTemplate_Render_concurrent(t *testing.T) {
+	engine := NewEngine()
+	engine.SetFileSystem(mapFileSystem{"item.html": "[{{ item }}]"})
+	tpl, err := engine.ParseTemplateLocation([]byte(
+		`{% assign total = 0 %}{% for x in items %}{% cycle "a", "b" %}{{ forloop.index }}{% assign total = total | plus: x %}{% endfor %}`+
+			`{% capture c %}{{ name | upcase }}{% endcapture %}{{ c }}={{ total }}`+
+			`{% tablerow x in items cols: 2 %}{{ x }}{% endtablerow %}`+
+			`{% include "item.html", item: name %}`), "page.html", 1)
+	require.NoError(t, err)
+
+	const count = 50
+	var (
+		wg      sync.WaitGroup
+		outputs = make([]string, count)
+		errs    = make([]error, count)
+	)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			items := make([]int, i%5+1)
+			for j := range items {
+				items[j] = i
+			}
+			out, err := tpl.RenderString(Bindings{"name": fmt.Sprintf("n%d", i), "items": items})
+			outputs[i], errs[i] = out, err
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < count; i++ {
+		require.NoError(t, errs[i])
+		n := i%5 + 1
+		expected := ""
+		for j := 0; j < n; j++ {
+			expected += fmt.Sprintf("%s%d", []string{"a", "b"}[j%2], j+1)
+		}
+		expected += fmt.Sprintf("N%d=%d", i, i*n)
+		require.True(t, strings.HasPrefix(outputs[i], expected), outputs[i])
+		require.True(t, strings.HasSuffix(outputs[i], fmt.Sprintf("[n%d]", i)), outputs[i])
+	}
+}
+
+type mapFileSystem map[string]string
+
+func (fs mapFileSystem) ReadFile(name string) ([]byte, error) {
+	if s, ok := fs[name]; ok {
+		return []byte(s), nil
+	}
+	return nil, os.ErrNotExist
+}
+
 func BenchmarkTemplate_Render(b *testing.B) {
 	engine := NewEngine()
 	bindings := Bindings{"a": "string value"}
@@ -115,3 +169,21 @@ func BenchmarkTemplate_Render(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+// BenchmarkTemplate_Render_filters measures the filter dispatch path. Filter arguments are
+// compiled when the template is parsed; a literal argument such as 20 evaluates to a value
+// that is created at that time.
+func BenchmarkTemplate_Render_filters(b *testing.B) {
+	engine := NewEngine()
+	bindings := Bindings{"s": "a string that is longer than twenty characters"}
+	tpl, err := engine.ParseString(`{% for i in (1..10000) %}{{ s | truncate: 20 | upcase }}{% endfor %}`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := tpl.Render(bindings)
+		require.NoError(b, err)
+	}
+}
@@ -2,18 +2,22 @@ package values
 
 import (
 	"reflect"
+	"unicode/utf8"
 )
 
 // TODO Length is now only used by the "size" filter.
 // Maybe it should go somewhere else.
 
-// Length returns the length of a string or array. In keeping with Liquid semantics,
-// and contra Go, it does not return the size of a map.
+// Length returns the number of characters (not bytes) of a string, the number of
+// elements of an array, or the number of keys of a map. It returns 0 for other values,
+// including nil.
 func Length(value interface{}) int {
 	value = ToLiquid(value)
 	ref := reflect.ValueOf(value)
 	switch ref.Kind() {
-	case reflect.Array, reflect.Slice, reflect.String:
+	case reflect.String:
+		return utf8.RuneCountInString(ref.String())
+	case reflect.Array, reflect.Slice, reflect.Map:
 		return ref.Len()
 	default:
 		return 0
@@ -13,7 +13,7 @@ import (
 // The function should return one or two values; the second value,
 // if present, should be an error.
 func Call(fn reflect.Value, args []interface{}) (interface{}, error) {
-	in, err := convertCallArguments(fn, args)
+	in, err := convertCallArguments(fn.Type(), args)
 	if err != nil {
 		return nil, err
 	}
@@ -21,6 +21,21 @@ func Call(fn reflect.Value, args []interface{}) (interface{}, error) {
 	return convertCallResults(results)
 }
 
+// CallWith is Call for a function some of whose parameters are supplied as is, instead of
+// from args. The args are converted to the parameter types of t, which is fn's type without
+// the leading parameters that receive before and the trailing parameters that receive after.
+func CallWith(fn reflect.Value, t reflect.Type, before []reflect.Value, args []interface{}, after []reflect.Value) (interface{}, error) {
+	in, err := convertCallArguments(t, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(before) > 0 || len(after) > 0 {
+		in = append(append(append(make([]reflect.Value, 0, len(before)+len(in)+len(after)), before...), in...), after...)
+	}
+	results := fn.Call(in)
+	return convertCallResults(results)
+}
+
 // A CallParityError is a mismatch between the argument and parameter counts.
 type CallParityError struct{ NumArgs, NumParams int }
 
@@ -40,9 +55,8 @@ func convertCallResults(results []reflect.Value) (interface{}, error) {
 	return results[0].Interface(), nil
 }
 
-// Convert args to match the input types of function fn.
-func convertCallArguments(fn reflect.Value, args []interface{}) (results []reflect.Value, err error) {
-	rt := fn.Type()
+// Convert args to match the input types of function type rt.
+func convertCallArguments(rt reflect.Type, args []interface{}) (results []reflect.Value, err error) {
 	if len(args) > rt.NumIn() && !rt.IsVariadic() {
 		return nil, &CallParityError{NumArgs: len(args), NumParams: rt.NumIn()}
 	}
@@ -89,13 +103,43 @@ func isDefaultFunctionType(typ reflect.Type) bool {
 	return typ.Kind() == reflect.Func && typ.NumIn() == 1 && typ.NumOut() == 1
 }
 
+// The types of the default functions that makeConstantFunction and makeIdentityFunction
+// create without reflect.MakeFunc, which is slow both to create a function and to call it.
+var (
+	boolFunctionType    = reflect.TypeOf(func(bool) bool { return false })
+	float64FunctionType = reflect.TypeOf(func(float64) float64 { return 0 })
+	intFunctionType     = reflect.TypeOf(func(int) int { return 0 })
+	stringFunctionType  = reflect.TypeOf(func(string) string { return "" })
+)
+
 func makeConstantFunction(typ reflect.Type, arg interface{}) reflect.Value {
+	out := typ.Out(0)
+	switch typ {
+	case boolFunctionType:
+		return reflect.ValueOf(func(bool) bool { return MustConvert(arg, out).(bool) })
+	case float64FunctionType:
+		return reflect.ValueOf(func(float64) float64 { return MustConvert(arg, out).(float64) })
+	case intFunctionType:
+		return reflect.ValueOf(func(int) int { return MustConvert(arg, out).(int) })
+	case stringFunctionType:
+		return reflect.ValueOf(func(string) string { return MustConvert(arg, out).(string) })
+	}
 	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
-		return []reflect.Value{reflect.ValueOf(MustConvert(arg, typ.Out(0)))}
+		return []reflect.Value{reflect.ValueOf(MustConvert(arg, out))}
 	})
 }
 
 func makeIdentityFunction(typ reflect.Type) reflect.Value {
+	switch typ {
+	case boolFunctionType:
+		return reflect.ValueOf(func(b bool) bool { return b })
+	case float64FunctionType:
+		return reflect.ValueOf(func(f float64) float64 { return f })
+	case intFunctionType:
+		return reflect.ValueOf(func(n int) int { return n })
+	case stringFunctionType:
+		return reflect.ValueOf(func(s string) string { return s })
+	}
 	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
 		return args
 	})
@@ -55,6 +55,43 @@ func TestCall_optional(t *testing.T) {
 	require.Equal(t, "5,10.", value)
 }
 
+func TestCall_optionalTypes(t *testing.T) {
+	fn := func(a int, b func(int) int, c func(float64) float64, d func(bool) bool, e func(interface{}) interface{}) string {
+		return fmt.Sprintf("%v %v %v %v", b(1), c(1.5), d(false), e("e"))
+	}
+	value, err := Call(reflect.ValueOf(fn), []interface{}{0})
+	require.NoError(t, err)
+	require.Equal(t, "1 1.5 false e", value)
+
+	value, err = Call(reflect.ValueOf(fn), []interface{}{0, "2", 3, true, "x"})
+	require.NoError(t, err)
+	require.Equal(t, "2 3 true x", value)
+
+	value, err = Call(reflect.ValueOf(fn), []interface{}{0, 2.7, "4.5", 1, 5})
+	require.NoError(t, err)
+	require.Equal(t, "2 4.5 true 5", value)
+}
+
+func TestCallWith(t *testing.T) {
+	fn := func(prefix string, a int, b func(int) int, suffix string) string {
+		return fmt.Sprint(prefix, a+b(1), suffix)
+	}
+	params := reflect.TypeOf(func(int, func(int) int) string { return "" })
+	before, after := []reflect.Value{reflect.ValueOf("<")}, []reflect.Value{reflect.ValueOf(">")}
+
+	value, err := CallWith(reflect.ValueOf(fn), params, before, []interface{}{"2"}, after)
+	require.NoError(t, err)
+	require.Equal(t, "<3>", value)
+
+	value, err = CallWith(reflect.ValueOf(fn), params, before, []interface{}{2, 10}, after)
+	require.NoError(t, err)
+	require.Equal(t, "<12>", value)
+
+	_, err = CallWith(reflect.ValueOf(fn), params, before, []interface{}{1, 2, 3}, after)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "given 3, expected 2")
+}
+
 func TestCall_variadic(t *testing.T) {
 	fn := func(sep func(string) string, args ...string) string {
 		return "[" + strings.Join(args, sep(",")) + "]"
@@ -1,20 +1,43 @@
 package values
 
 import (
+	"fmt"
 	"reflect"
+	"time"
 )
 
 var (
-	int64Type   = reflect.TypeOf(int64(0))
 	float64Type = reflect.TypeOf(float64(0))
+	uint64Type  = reflect.TypeOf(uint64(0))
 )
 
 // Equal returns a bool indicating whether a == b after conversion.
+//
+// A fmt.Stringer whose kind can't otherwise be compared to a string, such as an
+// enumeration whose underlying type is int, is compared to a string by its String value.
+//
+// nil is equal only to nil and Blank. Empty is equal to an empty string or collection;
+// Blank is equal to these, as well as to nil, false, and whitespace-only strings.
 func Equal(a, b interface{}) bool { // nolint: gocyclo
 	a, b = ToLiquid(a), ToLiquid(b)
+	switch {
+	case a == Empty:
+		return b == Empty || isEmptyCollection(b)
+	case b == Empty:
+		return isEmptyCollection(a)
+	case a == Blank:
+		return b == Blank || isBlank(b)
+	case b == Blank:
+		return isBlank(a)
+	}
 	if a == nil || b == nil {
 		return a == b
 	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Equal(tb)
+		}
+	}
 	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
 	switch joinKind(ra.Kind(), rb.Kind()) {
 	case reflect.Array, reflect.Slice:
@@ -29,8 +52,8 @@ func Equal(a, b interface{}) bool { // nolint: gocyclo
 		return true
 	case reflect.Bool:
 		return ra.Bool() == rb.Bool()
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return ra.Convert(int64Type).Int() == rb.Convert(int64Type).Int()
+	case reflect.Int64:
+		return compareInts(ra, rb) == 0
 	case reflect.Float32, reflect.Float64:
 		return ra.Convert(float64Type).Float() == rb.Convert(float64Type).Float()
 	case reflect.String:
@@ -40,59 +63,127 @@ func Equal(a, b interface{}) bool { // nolint: gocyclo
 			return ra.IsNil() == rb.IsNil()
 		}
 		return a == b
+	case reflect.Invalid:
+		if sa, sb, ok := stringerOperands(a, b); ok {
+			return sa == sb
+		}
+		return a == b
 	default:
 		return a == b
 	}
 }
 
 // Less returns a bool indicating whether a < b.
+//
+// nil, Empty, and Blank are neither less nor greater than any value. A fmt.Stringer is
+// compared to a string as it is by Equal.
 func Less(a, b interface{}) bool {
 	a, b = ToLiquid(a), ToLiquid(b)
-	if a == nil || b == nil {
+	switch {
+	case a == nil || b == nil, a == Empty || b == Empty, a == Blank || b == Blank:
 		return false
 	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Before(tb)
+		}
+	}
 	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
 	switch joinKind(ra.Kind(), rb.Kind()) {
 	case reflect.Bool:
 		return !ra.Bool() && rb.Bool()
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return ra.Convert(int64Type).Int() < rb.Convert(int64Type).Int()
+	case reflect.Int64:
+		return compareInts(ra, rb) < 0
 	case reflect.Float32, reflect.Float64:
 		return ra.Convert(float64Type).Float() < rb.Convert(float64Type).Float()
 	case reflect.String:
 		return ra.String() < rb.String()
+	case reflect.Invalid:
+		sa, sb, ok := stringerOperands(a, b)
+		return ok && sa < sb
 	default:
 		return false
 	}
 }
 
+// stringerOperands returns the string forms of a and b, if one is a string and the
+// other is a fmt.Stringer of a different kind.
+func stringerOperands(a, b interface{}) (string, string, bool) {
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if sa, ok := a.(fmt.Stringer); ok && rb.Kind() == reflect.String && ra.Kind() != reflect.String {
+		return sa.String(), rb.String(), true
+	}
+	if sb, ok := b.(fmt.Stringer); ok && ra.Kind() == reflect.String && rb.Kind() != reflect.String {
+		return ra.String(), sb.String(), true
+	}
+	return "", "", false
+}
+
+// joinKind returns the kind that a and b are compared as. Integers of different sizes
+// and signedness are compared as int64; an integer and a float, or two floats, are compared
+// as float64.
 func joinKind(a, b reflect.Kind) reflect.Kind { // nolint: gocyclo
-	if a == b {
+	switch {
+	case isIntKind(a) && isIntKind(b):
+		return reflect.Int64
+	case (isIntKind(a) || isFloatKind(a)) && (isIntKind(b) || isFloatKind(b)):
+		return reflect.Float64
+	case a == b:
 		return a
+	case (a == reflect.Array || a == reflect.Slice) && (b == reflect.Array || b == reflect.Slice):
+		return reflect.Slice
 	}
-	switch a {
-	case reflect.Array, reflect.Slice:
-		if b == reflect.Array || b == reflect.Slice {
-			return reflect.Slice
-		}
+	return reflect.Invalid
+}
+
+// compareInts returns -1, 0, or 1 according to whether the integer a is less than, equal
+// to, or greater than the integer b. Either may be signed or unsigned.
+func compareInts(a, b reflect.Value) int {
+	aSigned, bSigned := isSignedKind(a.Kind()), isSignedKind(b.Kind())
+	switch {
+	case aSigned && bSigned:
+		return compareInt64s(a.Int(), b.Int())
+	case aSigned && a.Int() < 0:
+		return -1
+	case bSigned && b.Int() < 0:
+		return 1
+	}
+	return compareUint64s(a.Convert(uint64Type).Uint(), b.Convert(uint64Type).Uint())
+}
+
+func compareInt64s(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64s(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	switch k {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if isIntKind(b) {
-			return reflect.Int64
-		}
-		if isFloatKind(b) {
-			return reflect.Float64
-		}
-	case reflect.Float32, reflect.Float64:
-		if isIntKind(b) || isFloatKind(b) {
-			return reflect.Float64
-		}
+		return true
+	default:
+		return false
 	}
-	return reflect.Invalid
 }
 
 func isIntKind(k reflect.Kind) bool {
 	switch k {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		return true
 	default:
 		return false
@@ -5,11 +5,22 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 )
 
 var eqTestObj = struct{ a, b int }{1, 2}
 var eqArrayTestObj = [2]int{1, 2}
 
+// testColor is an enumeration that implements fmt.Stringer.
+type testColor int
+
+func (c testColor) String() string { return [...]string{"blue", "green", "red"}[c] }
+
+// testStatus is a fmt.Stringer with a string kind.
+type testStatus string
+
+func (s testStatus) String() string { return "status " + string(s) }
+
 var eqTests = []struct {
 	a, b     interface{}
 	expected bool
@@ -27,8 +38,12 @@ var eqTests = []struct {
 	{1.0, 1, true},
 	{"a", "b", false},
 	{"a", "a", true},
-	{int8(2), int16(2), true}, // TODO
-	// {uint8(2), int8(2), true}, // FIXME
+	{int8(2), int16(2), true},
+	{uint8(2), int8(2), true},
+	{uint(2), 2.0, true},
+	{float32(2.5), 2.5, true},
+	{int64(2), float32(2), true},
+	{int8(-1), uint64(1<<64 - 1), false},
 	{eqArrayTestObj, eqArrayTestObj[:], true},
 	{[]string{"a"}, []string{"a"}, true},
 	{[]string{"a"}, []string{"a", "b"}, false},
@@ -37,6 +52,45 @@ var eqTests = []struct {
 	{[]string{"a", "b"}, []string{"a", "c"}, false},
 	{[]interface{}{1.0, 2}, []interface{}{1, 2.0}, true},
 	{eqTestObj, eqTestObj, true},
+	{nil, "", false},
+	{"", nil, false},
+	{nil, 0, false},
+	{Empty, Empty, true},
+	{Empty, nil, false},
+	{nil, Empty, false},
+	{Empty, "", true},
+	{"", Empty, true},
+	{"a", Empty, false},
+	{Empty, []string{}, true},
+	{[]string{"a"}, Empty, false},
+	{map[string]int{}, Empty, true},
+	{map[string]int{"a": 1}, Empty, false},
+	{yaml.MapSlice{}, Empty, true},
+	{false, Empty, false},
+	{0, Empty, false},
+	{Blank, Blank, true},
+	{Blank, nil, true},
+	{nil, Blank, true},
+	{false, Blank, true},
+	{true, Blank, false},
+	{"", Blank, true},
+	{" \t\n", Blank, true},
+	{" a ", Blank, false},
+	{Blank, []string{}, true},
+	{map[string]int{}, Blank, true},
+	{[]string{" "}, Blank, false},
+	{0, Blank, false},
+	{Blank, Empty, false},
+	{" ", Empty, false},
+	{testColor(2), "red", true},
+	{"green", testColor(1), true},
+	{testColor(2), "blue", false},
+	{testColor(2), 2, true},
+	{testColor(2), testColor(2), true},
+	{testStatus("ok"), "ok", true},
+	{testStatus("ok"), "status ok", false},
+	{timeMustParse("2017-07-09T10:40:00Z"), timeMustParse("2017-07-09T06:40:00-04:00"), true},
+	{timeMustParse("2017-07-09T10:40:00Z"), timeMustParse("2017-07-09T10:40:00-04:00"), false},
 }
 
 func TestEqual(t *testing.T) {
@@ -68,3 +122,10 @@ func TestEqual_ptr(t *testing.T) {
 	// require.True(t, Equal(pn, nil)) // TODO
 	// require.True(t, Equal(nil, pn)) // TODO
 }
+
+func TestLess_time(t *testing.T) {
+	a, b := timeMustParse("2017-07-09T10:40:00Z"), timeMustParse("2017-07-10T10:40:00Z")
+	require.True(t, Less(a, b))
+	require.False(t, Less(b, a))
+	require.False(t, Less(a, a))
+}
@@ -3,8 +3,10 @@ package values
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	yaml "gopkg.in/yaml.v2"
@@ -81,6 +83,9 @@ func convertValueToFloat(value interface{}, typ reflect.Type) (float64, error) {
 func Convert(value interface{}, typ reflect.Type) (interface{}, error) { // nolint: gocyclo
 	value = ToLiquid(value)
 	rv := reflect.ValueOf(value)
+	if value != nil && rv.Type() == typ {
+		return value, nil
+	}
 	// int.Convert(string) returns "\x01" not "1", so guard against that in the following test
 	if typ.Kind() != reflect.String && value != nil && rv.Type().ConvertibleTo(typ) {
 		return rv.Convert(typ).Interface(), nil
@@ -233,6 +238,10 @@ func Convert(value interface{}, typ reflect.Type) (interface{}, error) { // noli
 			return string(value), nil
 		case fmt.Stringer:
 			return value.String(), nil
+		case float32:
+			return FormatFloat(float64(value), 32), nil
+		case float64:
+			return FormatFloat(value, 64), nil
 		default:
 			return fmt.Sprint(value), nil
 		}
@@ -240,6 +249,17 @@ func Convert(value interface{}, typ reflect.Type) (interface{}, error) { // noli
 	return nil, conversionError("", value, typ)
 }
 
+// FormatFloat formats f as Liquid does: with as few decimal digits as represent it exactly,
+// but at least one. For example, 1.0 formats as "1.0" and 1.50 as "1.5". bitSize is 32
+// for a float32 and 64 for a float64.
+func FormatFloat(f float64, bitSize int) string {
+	s := strconv.FormatFloat(f, 'f', -1, bitSize)
+	if math.IsInf(f, 0) || math.IsNaN(f) || strings.ContainsRune(s, '.') {
+		return s
+	}
+	return s + ".0"
+}
+
 // MustConvert is like Convert, but panics if conversion fails.
 func MustConvert(value interface{}, t reflect.Type) interface{} {
 	out, err := Convert(value, t)
@@ -70,6 +70,10 @@ var convertTests = []struct {
 	{"2.1", float32(2.1)},
 	{"2.1", float64(2.1)},
 	{"string", "string"},
+	{1, "1"},
+	{1.0, "1.0"},
+	{1.5, "1.5"},
+	{float32(2.1), "2.1"},
 	{[]interface{}{1, 2}, []interface{}{1, 2}},
 	{[]int{1, 2}, []int{1, 2}},
 	{[]int{1, 2}, []interface{}{1, 2}},
@@ -8,6 +8,13 @@ type drop interface {
 	ToLiquid() interface{}
 }
 
+// A propertyDrop is a drop that resolves its own properties, instead of
+// exposing the properties of its ToLiquid value.
+type propertyDrop interface {
+	drop
+	LiquidProperty(name string) (interface{}, bool)
+}
+
 // ToLiquid converts an object to Liquid, if it implements the Drop interface.
 func ToLiquid(value interface{}) interface{} {
 	switch value := value.(type) {
@@ -37,3 +44,36 @@ func (w *dropWrapper) Int() int                    { return w.Resolve().Int() }
 func (w *dropWrapper) Interface() interface{}      { return w.Resolve().Interface() }
 func (w *dropWrapper) PropertyValue(k Value) Value { return w.Resolve().PropertyValue(k) }
 func (w *dropWrapper) Test() bool                  { return w.Resolve().Test() }
+
+// A propertyDropWrapper dispatches property and string index access to the drop's
+// LiquidProperty method. Other operations apply to the drop's ToLiquid value.
+type propertyDropWrapper struct {
+	*dropWrapper
+	pd propertyDrop
+}
+
+func (w propertyDropWrapper) IndexValue(i Value) Value {
+	if v, ok := w.property(i); ok {
+		return v
+	}
+	return w.dropWrapper.IndexValue(i)
+}
+
+func (w propertyDropWrapper) PropertyValue(k Value) Value {
+	if v, ok := w.property(k); ok {
+		return v
+	}
+	return w.dropWrapper.PropertyValue(k)
+}
+
+func (w propertyDropWrapper) property(k Value) (Value, bool) {
+	name, ok := k.Interface().(string)
+	if !ok {
+		return nil, false
+	}
+	v, ok := w.pd.LiquidProperty(name)
+	if !ok {
+		return nil, false
+	}
+	return ValueOf(v), true
+}
@@ -23,6 +23,38 @@ func TestValue_drop(t *testing.T) {
 	require.Equal(t, 7, dv.PropertyValue(ValueOf("size")).Interface())
 }
 
+type testPropertyDrop struct {
+	calls *int
+}
+
+func (d testPropertyDrop) ToLiquid() interface{} { return []int{1, 2, 3} }
+
+func (d testPropertyDrop) LiquidProperty(name string) (interface{}, bool) {
+	*d.calls++
+	if name == "computed" {
+		return *d.calls * 10, true
+	}
+	return nil, false
+}
+
+func TestValue_propertyDrop(t *testing.T) {
+	calls := 0
+	dv := ValueOf(testPropertyDrop{&calls})
+	require.Equal(t, 10, dv.PropertyValue(ValueOf("computed")).Interface())
+	require.Equal(t, 20, dv.IndexValue(ValueOf("computed")).Interface())
+	require.Equal(t, 2, calls)
+
+	// undefined properties fall back to the ToLiquid value
+	require.Equal(t, 3, dv.PropertyValue(ValueOf("size")).Interface())
+	require.Equal(t, 1, dv.PropertyValue(ValueOf("first")).Interface())
+	require.Equal(t, 2, dv.IndexValue(ValueOf(1)).Interface())
+	require.Equal(t, nil, dv.PropertyValue(ValueOf("missing")).Interface())
+
+	// other operations use the ToLiquid value
+	require.Equal(t, []int{1, 2, 3}, dv.Interface())
+	require.True(t, dv.Contains(ValueOf(2)))
+}
+
 func TestDrop_Resolve_race(t *testing.T) {
 	d := ValueOf(testDrop{1})
 	values := make(chan int, 2)
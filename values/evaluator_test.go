@@ -12,6 +12,12 @@ var lessTests = []struct {
 	expected bool
 }{
 	{nil, nil, false},
+	{nil, "a", false},
+	{"a", nil, false},
+	{nil, 1, false},
+	{1, nil, false},
+	{Empty, "a", false},
+	{"", Empty, false},
 	{false, true, true},
 	{false, false, false},
 	{false, nil, false},
@@ -22,9 +28,24 @@ var lessTests = []struct {
 	{1, 2.1, true},
 	{1.1, 2, true},
 	{2.1, 1, false},
+	{1, 1.5, true},
+	{1.5, 1, false},
+	{2, 2.0, false},
+	{2.0, 2, false},
+	{int8(1), int64(2), true},
+	{uint8(1), 2, true},
+	{2, uint8(1), false},
+	{-1, uint(0), true},
+	{uint(0), -1, false},
+	{uint64(1<<64 - 1), int64(1), false},
+	{float32(1.5), 2, true},
 	{"a", "b", true},
 	{"b", "a", false},
 	{[]string{"a"}, []string{"a"}, false},
+	{testColor(0), "green", true},
+	{"red", testColor(1), false},
+	{testColor(2), "red", false},
+	{testColor(1), testColor(2), true},
 }
 
 func TestLess(t *testing.T) {
@@ -39,7 +60,11 @@ func TestLess(t *testing.T) {
 func TestLength(t *testing.T) {
 	require.Equal(t, 3, Length([]int{1, 2, 3}))
 	require.Equal(t, 3, Length("abc"))
-	require.Equal(t, 0, Length(map[string]int{"a": 1}))
+	require.Equal(t, 5, Length("héllo"))
+	require.Equal(t, 2, Length([][]int{{1, 2}, {3}}))
+	require.Equal(t, 1, Length(map[string]int{"a": 1}))
+	require.Equal(t, 0, Length(nil))
+	require.Equal(t, 0, Length(12))
 }
 
 func TestSort(t *testing.T) {
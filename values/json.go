@@ -0,0 +1,73 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ToJSON returns the JSON encoding of value, as the jsonify filter and the json_stream tag
+// write it. It is json.Marshal, except that a drop, whether it is value or an element of
+// one of value's maps, slices, or arrays, is encoded as the value of its ToLiquid method.
+// Structs, including their json field tags, are encoded as json.Marshal encodes them.
+func ToJSON(value interface{}) ([]byte, error) {
+	v, err := jsonValue(value, map[jsonCycleKey]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+var interfaceType = reflect.TypeOf([]interface{}{}).Elem()
+
+// A jsonCycleKey identifies a map or slice that jsonValue is converting.
+type jsonCycleKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// jsonValue returns a copy of value in which drops are replaced by their ToLiquid values.
+// Maps, slices, and arrays that don't contain drops are copied too, with interface{}
+// elements; this doesn't change their encoding.
+func jsonValue(value interface{}, active map[jsonCycleKey]bool) (interface{}, error) {
+	if d, ok := value.(drop); ok {
+		return jsonValue(d.ToLiquid(), active)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() || rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			// json encodes a []byte as a base64 string
+			return value, nil
+		}
+		key := jsonCycleKey{rv.Type(), rv.Pointer()}
+		if active[key] {
+			return nil, fmt.Errorf("json: unsupported value: %T contains itself", value)
+		}
+		active[key] = true
+		defer delete(active, key)
+	case reflect.Array:
+	default:
+		return value, nil
+	}
+	if rv.Kind() == reflect.Map {
+		m := reflect.MakeMapWithSize(reflect.MapOf(rv.Type().Key(), interfaceType), rv.Len())
+		for _, k := range rv.MapKeys() {
+			e, err := jsonValue(rv.MapIndex(k).Interface(), active)
+			if err != nil {
+				return nil, err
+			}
+			m.SetMapIndex(k, reflect.ValueOf(&e).Elem())
+		}
+		return m.Interface(), nil
+	}
+	a := make([]interface{}, rv.Len())
+	for i := range a {
+		e, err := jsonValue(rv.Index(i).Interface(), active)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = e
+	}
+	return a, nil
+}
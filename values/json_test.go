@@ -0,0 +1,49 @@
+package values
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type jsonTestStruct struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value,omitempty"`
+	skip  int
+}
+
+func TestToJSON(t *testing.T) {
+	calls := 0
+	tests := []struct {
+		in       interface{}
+		expected string
+	}{
+		{nil, `null`},
+		{"a<b", `"a\u003cb"`},
+		{[]int{1, 2}, `[1,2]`},
+		{[]interface{}(nil), `null`},
+		{[]byte("ab"), `"YWI="`},
+		{[2]string{"a", "b"}, `["a","b"]`},
+		{map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{map[int]string{1: "a"}, `{"1":"a"}`},
+		{jsonTestStruct{Name: "x", skip: 1}, `{"name":"x"}`},
+		{testDrop{"seafood"}, `"seafood"`},
+		{testDrop{testDrop{1}}, `1`},
+		{[]interface{}{testDrop{1}, map[string]interface{}{"d": testDrop{[]string{"x"}}}}, `[1,{"d":["x"]}]`},
+		{testDrop{jsonTestStruct{Name: "y"}}, `{"name":"y"}`},
+		{testPropertyDrop{&calls}, `[1,2,3]`},
+	}
+	for _, test := range tests {
+		b, err := ToJSON(test.in)
+		require.NoError(t, err, test.in)
+		require.Equal(t, test.expected, string(b), test.in)
+	}
+	require.Zero(t, calls)
+
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	_, err := ToJSON(cyclic)
+	require.Error(t, err)
+	_, err = ToJSON(make(chan int))
+	require.Error(t, err)
+}
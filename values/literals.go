@@ -0,0 +1,54 @@
+package values
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Empty is the value of the Liquid empty literal, as in {% if items == empty %}.
+//
+// Empty is equal to an empty string, array, slice, or map, and to itself. It isn't equal
+// to nil, false, or any other value, and it isn't less or greater than any value.
+// It renders as an empty string.
+var Empty = emptyLiteral{}
+
+// Blank is the value of the Liquid blank literal, as in {% if name == blank %}.
+//
+// Blank is equal to the values that Empty is equal to, and also to nil, false, and a
+// string that contains only whitespace. Like Empty, it isn't ordered, and it renders as an
+// empty string.
+var Blank = blankLiteral{}
+
+type emptyLiteral struct{}
+type blankLiteral struct{}
+
+func (emptyLiteral) String() string { return "" }
+func (blankLiteral) String() string { return "" }
+
+// isEmptyCollection returns a bool indicating whether value is an empty string or collection.
+func isEmptyCollection(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	r := reflect.ValueOf(value)
+	switch r.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return r.Len() == 0
+	default:
+		return false
+	}
+}
+
+// isBlank returns a bool indicating whether value is nil, false, an empty collection,
+// or a string that contains only whitespace.
+func isBlank(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return strings.TrimSpace(v) == ""
+	}
+	return isEmptyCollection(value)
+}
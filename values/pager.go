@@ -0,0 +1,29 @@
+package values
+
+// A Pager is a collection that is fetched a page at a time, such as the results of a
+// paginated API. A {% for %} loop over a Pager fetches each page when it reaches it.
+type Pager interface {
+	// Page returns at most limit items, starting with the item at offset, and
+	// whether there are items after these.
+	Page(offset, limit int) (items []interface{}, more bool)
+}
+
+// PagerFunc adapts a function to a Pager.
+type PagerFunc func(offset, limit int) ([]interface{}, bool)
+
+// Page calls f(offset, limit).
+func (f PagerFunc) Page(offset, limit int) ([]interface{}, bool) {
+	return f(offset, limit)
+}
+
+// AsPager returns value as a Pager, if it is a Pager or a function with the signature
+// of PagerFunc.
+func AsPager(value interface{}) (Pager, bool) {
+	switch v := value.(type) {
+	case Pager:
+		return v, true
+	case func(offset, limit int) ([]interface{}, bool):
+		return PagerFunc(v), true
+	}
+	return nil, false
+}
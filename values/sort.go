@@ -5,9 +5,9 @@ import (
 	"sort"
 )
 
-// Sort any []interface{} value.
+// Sort any []interface{} value. The sort is stable: equal elements keep their order.
 func Sort(data []interface{}) {
-	sort.Sort(genericSortable(data))
+	sort.Stable(genericSortable(data))
 }
 
 type genericSortable []interface{}
@@ -27,9 +27,10 @@ func (s genericSortable) Less(i, j int) bool {
 	return Less(s[i], s[j])
 }
 
-// SortByProperty sorts maps on their key indices.
+// SortByProperty sorts maps on their key indices. The sort is stable: elements with equal
+// values at key, or that don't have the key, keep their order.
 func SortByProperty(data []interface{}, key string, nilFirst bool) {
-	sort.Sort(sortableByProperty{data, key, nilFirst})
+	sort.Stable(sortableByProperty{data, key, nilFirst})
 }
 
 type sortableByProperty struct {
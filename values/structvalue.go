@@ -1,11 +1,27 @@
 package values
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 )
 
 type structValue struct{ wrapperValue }
 
+// A PropertyError is an error returned by a method or function-valued field,
+// that was invoked in order to retrieve a property value.
+type PropertyError struct {
+	Property string
+	Err      error
+}
+
+func (e PropertyError) Error() string {
+	return fmt.Sprintf("error retrieving property %q: %s", e.Property, e.Err)
+}
+
+// Cause returns the error that was returned by the method.
+func (e PropertyError) Cause() error { return e.Err }
+
 func (sv structValue) IndexValue(index Value) Value {
 	return sv.PropertyValue(index)
 }
@@ -28,6 +44,9 @@ func (sv structValue) Contains(elem Value) bool {
 	if _, found := sv.findField(name); found {
 		return true
 	}
+	if _, found := findMethod(reflect.TypeOf(sv.value), name); found {
+		return true
+	}
 	return false
 }
 
@@ -41,7 +60,7 @@ func (sv structValue) PropertyValue(index Value) Value {
 	if st.Kind() == reflect.Ptr {
 		if _, found := st.MethodByName(name); found {
 			m := sr.MethodByName(name)
-			return sv.invoke(m)
+			return sv.invoke(m, name)
 		}
 		st = st.Elem()
 		sr = sr.Elem()
@@ -51,21 +70,31 @@ func (sv structValue) PropertyValue(index Value) Value {
 	}
 	if _, ok := st.MethodByName(name); ok {
 		m := sr.MethodByName(name)
-		return sv.invoke(m)
+		return sv.invoke(m, name)
 	}
 	if field, ok := sv.findField(name); ok {
 		fv := sr.FieldByName(field.Name)
 		if fv.Kind() == reflect.Func {
-			return sv.invoke(fv)
+			return sv.invoke(fv, name)
 		}
 		return ValueOf(fv.Interface())
 	}
+	if m, ok := findMethod(reflect.TypeOf(sv.value), name); ok {
+		return sv.invoke(reflect.ValueOf(sv.value).Method(m.Index), name)
+	}
 	return nilValue
 }
 
-const tagKey = "liquid"
+const (
+	tagKey     = "liquid"
+	jsonTagKey = "json"
+)
 
-// like FieldByName, but obeys `liquid:"name"` tags
+// like FieldByName, but obeys `liquid:"name"` tags.
+//
+// A field is matched by, in order of precedence: its name, if it doesn't have a liquid tag;
+// its liquid tag; its json tag, if it doesn't have a liquid tag; and a case-insensitive
+// match of its name, if it doesn't have either tag.
 func (sv structValue) findField(name string) (*reflect.StructField, bool) {
 	sr := reflect.TypeOf(sv.value)
 	if sr.Kind() == reflect.Ptr {
@@ -82,10 +111,49 @@ func (sv structValue) findField(name string) (*reflect.StructField, bool) {
 			return &field, true
 		}
 	}
+	for i, n := 0, sr.NumField(); i < n; i++ {
+		field := sr.Field(i)
+		if _, ok := field.Tag.Lookup(tagKey); ok || field.PkgPath != "" {
+			continue
+		}
+		if tag := strings.Split(field.Tag.Get(jsonTagKey), ",")[0]; tag != "" && tag != "-" && tag == name {
+			return &field, true
+		}
+	}
+	for i, n := 0, sr.NumField(); i < n; i++ {
+		field := sr.Field(i)
+		if _, ok := field.Tag.Lookup(tagKey); ok || field.PkgPath != "" {
+			continue
+		}
+		if _, ok := field.Tag.Lookup(jsonTagKey); ok {
+			continue
+		}
+		if strings.EqualFold(field.Name, name) {
+			return &field, true
+		}
+	}
 	return nil, false
 }
 
-func (sv structValue) invoke(fv reflect.Value) Value {
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// findMethod finds an exported method whose name is a case-insensitive match for name,
+// that takes no arguments and returns either a value, or a value and an error.
+func findMethod(t reflect.Type, name string) (reflect.Method, bool) {
+	for i, n := 0, t.NumMethod(); i < n; i++ {
+		m := t.Method(i)
+		mt := m.Type
+		if !strings.EqualFold(m.Name, name) || mt.NumIn() != 1 {
+			continue
+		}
+		if mt.NumOut() == 1 || (mt.NumOut() == 2 && mt.Out(1) == errorType) {
+			return m, true
+		}
+	}
+	return reflect.Method{}, false
+}
+
+func (sv structValue) invoke(fv reflect.Value, name string) Value {
 	if fv.IsNil() {
 		return nilValue
 	}
@@ -95,6 +163,9 @@ func (sv structValue) invoke(fv reflect.Value) Value {
 	}
 	results := fv.Call([]reflect.Value{})
 	if len(results) > 1 && !results[1].IsNil() {
+		if err, ok := results[1].Interface().(error); ok {
+			panic(PropertyError{Property: name, Err: err})
+		}
 		panic(results[1].Interface())
 	}
 	return ValueOf(results[0].Interface())
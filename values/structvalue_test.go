@@ -89,3 +89,79 @@ func TestValue_struct_ptr(t *testing.T) {
 	require.Equal(t, 4, p.PropertyValue(ValueOf("PM2")).Interface())
 	require.Panics(t, func() { p.PropertyValue(ValueOf("PM2e")) })
 }
+
+type testTaggedStruct struct {
+	FirstName string `liquid:"firstName"`
+	LastName  string `json:"lastName,omitempty"`
+	Email     string `json:"-"`
+	Nickname  string
+	Both      string `liquid:"both" json:"jsonBoth"`
+	hidden    string
+}
+
+func TestValue_struct_tags(t *testing.T) {
+	s := ValueOf(testTaggedStruct{
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+		Email:     "ada@example.com",
+		Nickname:  "Countess",
+		Both:      "both",
+		hidden:    "hidden",
+	})
+
+	// liquid tag
+	require.Equal(t, "Ada", s.PropertyValue(ValueOf("firstName")).Interface())
+	require.Equal(t, nil, s.PropertyValue(ValueOf("FirstName")).Interface())
+	require.Equal(t, nil, s.PropertyValue(ValueOf("firstname")).Interface())
+
+	// json tag fallback
+	require.Equal(t, "Lovelace", s.PropertyValue(ValueOf("lastName")).Interface())
+	require.Equal(t, "Lovelace", s.PropertyValue(ValueOf("LastName")).Interface())
+	require.True(t, s.Contains(ValueOf("lastName")))
+	require.Equal(t, "ada@example.com", s.PropertyValue(ValueOf("Email")).Interface())
+	require.Equal(t, nil, s.PropertyValue(ValueOf("-")).Interface())
+
+	// the liquid tag takes precedence over the json tag
+	require.Equal(t, "both", s.PropertyValue(ValueOf("both")).Interface())
+	require.Equal(t, nil, s.PropertyValue(ValueOf("jsonBoth")).Interface())
+
+	// case-insensitive field name
+	require.Equal(t, "Countess", s.PropertyValue(ValueOf("nickname")).Interface())
+	require.Equal(t, "Countess", s.IndexValue(ValueOf("NICKNAME")).Interface())
+	require.True(t, s.Contains(ValueOf("nickname")))
+	require.Equal(t, nil, s.PropertyValue(ValueOf("Hidden")).Interface())
+}
+
+type testUser struct{ First, Last string }
+
+func (u testUser) FullName() string                { return u.First + " " + u.Last }
+func (u *testUser) Initials() string               { return u.First[:1] + u.Last[:1] }
+func (u testUser) Verified() (bool, error)         { return true, nil }
+func (u testUser) Balance() (int, error)           { return 0, fmt.Errorf("account locked") }
+func (u testUser) Greeting(greeting string) string { return greeting + ", " + u.First }
+
+func TestValue_struct_methods(t *testing.T) {
+	u := testUser{"Ada", "Lovelace"}
+	s, p := ValueOf(u), ValueOf(&u)
+
+	// value receiver
+	require.Equal(t, "Ada Lovelace", s.PropertyValue(ValueOf("fullName")).Interface())
+	require.Equal(t, "Ada Lovelace", s.IndexValue(ValueOf("fullname")).Interface())
+	require.Equal(t, "Ada Lovelace", p.PropertyValue(ValueOf("fullName")).Interface())
+	require.True(t, s.Contains(ValueOf("fullName")))
+
+	// pointer receiver
+	require.Equal(t, "AL", p.PropertyValue(ValueOf("initials")).Interface())
+	require.Equal(t, nil, s.PropertyValue(ValueOf("initials")).Interface())
+
+	// (T, error) results
+	require.Equal(t, true, s.PropertyValue(ValueOf("verified")).Interface())
+	require.PanicsWithError(t, `error retrieving property "balance": account locked`, func() {
+		s.PropertyValue(ValueOf("balance"))
+	})
+
+	// fields take precedence over methods; methods that take arguments are ignored
+	require.Equal(t, "Ada", s.PropertyValue(ValueOf("first")).Interface())
+	require.Equal(t, nil, s.PropertyValue(ValueOf("greeting")).Interface())
+	require.False(t, s.Contains(ValueOf("greeting")))
+}
@@ -1,9 +1,9 @@
 package values
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 
 	yaml "gopkg.in/yaml.v2"
 )
@@ -29,21 +29,32 @@ type Value interface {
 // ValueOf returns a Value that wraps its argument.
 // If the argument is already a Value, it returns this.
 func ValueOf(value interface{}) Value { // nolint: gocyclo
-	// interned values
-	switch value {
+	// common scalar types, and interned values, without reflection
+	switch v := value.(type) {
 	case nil:
 		return nilValue
-	case true:
-		return trueValue
-	case false:
+	case bool:
+		if v {
+			return trueValue
+		}
 		return falseValue
-	case 0:
-		return zeroValue
-	case 1:
-		return oneValue
+	case int:
+		switch v {
+		case 0:
+			return zeroValue
+		case 1:
+			return oneValue
+		}
+		return wrapperValue{value}
+	case int64, float64:
+		return wrapperValue{value}
+	case string:
+		return stringValue{wrapperValue{value}}
 	}
 	// interfaces
 	switch v := value.(type) {
+	case propertyDrop:
+		return propertyDropWrapper{&dropWrapper{d: v}, v}
 	case drop:
 		return &dropWrapper{d: v}
 	case yaml.MapSlice:
@@ -106,12 +117,14 @@ func (v wrapperValue) Int() int {
 	panic(conversionError("", v.value, reflect.TypeOf(1)))
 }
 
-// interned values
-var nilValue = wrapperValue{nil}
-var falseValue = wrapperValue{false}
-var trueValue = wrapperValue{true}
-var zeroValue = wrapperValue{0}
-var oneValue = wrapperValue{1}
+// interned values. These have type Value, so that returning one doesn't allocate.
+var (
+	nilValue   Value = wrapperValue{nil}
+	falseValue Value = wrapperValue{false}
+	trueValue  Value = wrapperValue{true}
+	zeroValue  Value = wrapperValue{0}
+	oneValue   Value = wrapperValue{1}
+)
 
 // container values
 type arrayValue struct{ wrapperValue }
@@ -169,11 +182,20 @@ func (av arrayValue) PropertyValue(iv Value) Value {
 	return nilValue
 }
 
+// Contains returns true if the map has the key iv. Keys of other types are converted to
+// the key type of the map, if they are of the same kind or are both integers.
 func (mv mapValue) Contains(iv Value) bool {
 	mr := reflect.ValueOf(mv.value)
 	ir := reflect.ValueOf(iv.Interface())
-	if ir.IsValid() && mr.Type().Key() == ir.Type() {
+	if !ir.IsValid() || !ir.Type().Comparable() {
+		return false
+	}
+	kt := mr.Type().Key()
+	switch {
+	case ir.Type() == kt, kt.Kind() == reflect.Interface:
 		return mr.MapIndex(ir).IsValid()
+	case ir.Type().ConvertibleTo(kt) && (ir.Kind() == kt.Kind() || isIntKind(ir.Kind()) && isIntKind(kt.Kind())):
+		return mr.MapIndex(ir.Convert(kt)).IsValid()
 	}
 	return false
 }
@@ -208,17 +230,35 @@ func (mv mapValue) PropertyValue(iv Value) Value {
 	}
 }
 
+// Contains returns true if substr, or its string form if it isn't a string, is a substring.
+// nil isn't a substring of any string.
 func (sv stringValue) Contains(substr Value) bool {
-	s, ok := substr.Interface().(string)
-	if !ok {
-		s = fmt.Sprint(substr.Interface())
+	switch v := substr.Interface().(type) {
+	case nil:
+		return false
+	case string:
+		return strings.Contains(sv.value.(string), v)
+	default:
+		s, err := Convert(v, reflect.TypeOf(""))
+		return err == nil && strings.Contains(sv.value.(string), s.(string))
 	}
-	return strings.Contains(sv.value.(string), s)
 }
 
+// PropertyValue returns the first and last characters, and the length, of a string.
+// first and last are nil if the string is empty.
 func (sv stringValue) PropertyValue(iv Value) Value {
-	if iv.Interface() == sizeKey {
-		return ValueOf(len(sv.value.(string)))
+	s := sv.value.(string)
+	switch iv.Interface() {
+	case firstKey:
+		if r, n := utf8.DecodeRuneInString(s); n > 0 {
+			return ValueOf(string(r))
+		}
+	case lastKey:
+		if r, n := utf8.DecodeLastRuneInString(s); n > 0 {
+			return ValueOf(string(r))
+		}
+	case sizeKey:
+		return ValueOf(utf8.RuneCountInString(s))
 	}
 	return nilValue
 }
@@ -131,6 +131,10 @@ func TestValue_Contains(t *testing.T) {
 	require.False(t, av.Contains(ValueOf(nil)))
 
 	require.True(t, ValueOf([]interface{}{nil}).Contains(ValueOf(nil)))
+	require.True(t, ValueOf([]interface{}{1.0, "a"}).Contains(ValueOf(1)))
+	require.True(t, ValueOf([]int64{1, 2}).Contains(ValueOf(uint8(2))))
+	require.True(t, ValueOf([]interface{}{[]int{1}}).Contains(ValueOf([]interface{}{1})))
+	require.False(t, ValueOf([]interface{}{"1"}).Contains(ValueOf(1)))
 
 	// string
 	sv := ValueOf("seafood")
@@ -140,18 +144,30 @@ func TestValue_Contains(t *testing.T) {
 
 	// string contains stringifies its argument
 	require.True(t, ValueOf("seaf00d").Contains(ValueOf(0)))
+	require.True(t, ValueOf("pi is 3.5").Contains(ValueOf(3.5)))
+	require.False(t, ValueOf("<nil>").Contains(ValueOf(nil)))
+	require.True(t, ValueOf("").Contains(ValueOf("")))
 
 	// map
 	hv := ValueOf(map[string]interface{}{"key": "value"})
 	require.True(t, hv.Contains(ValueOf("key")))
 	require.False(t, hv.Contains(ValueOf("missing_key")))
 	require.False(t, hv.Contains(ValueOf(nil)))
+	require.False(t, hv.Contains(ValueOf("value")))
+	require.False(t, hv.Contains(ValueOf([]string{"key"})))
+	require.False(t, ValueOf(map[string]int{"A": 1}).Contains(ValueOf(65)))
+	require.True(t, ValueOf(map[interface{}]interface{}{"key": 1}).Contains(ValueOf("key")))
+	require.True(t, ValueOf(map[int]string{1: "a"}).Contains(ValueOf(int64(1))))
+	type key string
+	require.True(t, ValueOf(map[key]int{"k": 1}).Contains(ValueOf("k")))
 
 	// MapSlice
 	msv := ValueOf(yaml.MapSlice{{Key: "key", Value: "value"}})
 	require.True(t, msv.Contains(ValueOf("key")))
 	require.False(t, msv.Contains(ValueOf("missing_key")))
 	require.False(t, msv.Contains(ValueOf(nil)))
+	require.True(t, ValueOf(yaml.MapSlice{{Key: 1, Value: "value"}}).Contains(ValueOf(1.0)))
+	require.False(t, ValueOf(yaml.MapSlice{{Key: []int{1}, Value: "value"}}).Contains(ValueOf([]int{2})))
 }
 
 func TestValue_PropertyValue_size(t *testing.T) {
@@ -161,6 +177,10 @@ func TestValue_PropertyValue_size(t *testing.T) {
 
 	// string
 	require.Equal(t, 7, ValueOf("seafood").PropertyValue(ValueOf("size")).Interface())
+	require.Equal(t, "s", ValueOf("seafood").PropertyValue(ValueOf("first")).Interface())
+	require.Equal(t, "é", ValueOf("café").PropertyValue(ValueOf("last")).Interface())
+	require.Nil(t, ValueOf("").PropertyValue(ValueOf("first")).Interface())
+	require.Nil(t, ValueOf("").PropertyValue(ValueOf("last")).Interface())
 
 	// empty list
 	empty := ValueOf([]string{})
@@ -190,3 +210,18 @@ func TestValue_PropertyValue_size(t *testing.T) {
 	msv = ValueOf(yaml.MapSlice{{Key: "size", Value: "value"}})
 	require.Equal(t, "value", msv.PropertyValue(ValueOf("size")).Interface())
 }
+
+var benchmarkValue Value
+
+// BenchmarkValueOf_scalars measures ValueOf on the scalar types that literals and most
+// variables have.
+func BenchmarkValueOf_scalars(b *testing.B) {
+	scalars := []interface{}{nil, "a string", 2, int64(3), 4.5, true, false, 0, 1}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range scalars {
+			benchmarkValue = ValueOf(v)
+		}
+	}
+}